@@ -0,0 +1,116 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"strings"
+)
+
+// Category is the kind of failure an error from the API represents.
+type Category int
+
+const (
+	// CategoryUnknown covers nil, requests whose context was cancelled and
+	// errors that didn't come from a request, such as a response that
+	// failed to decode.
+	CategoryUnknown Category = iota
+
+	// CategoryNetwork means the request never got a response: the server
+	// was unreachable, the connection dropped or the request timed out.
+	CategoryNetwork
+
+	// CategoryRateLimited means the server answered 429.
+	CategoryRateLimited
+
+	// CategoryUnauthorized means the server rejected the token (401).
+	CategoryUnauthorized
+
+	// CategoryClient means the server rejected the request itself (other
+	// 4xx). Sending it again won't help.
+	CategoryClient
+
+	// CategoryServer means the server failed (5xx).
+	CategoryServer
+)
+
+func (c Category) String() string {
+	switch c {
+	case CategoryNetwork:
+		return "network"
+	case CategoryRateLimited:
+		return "rate_limited"
+	case CategoryUnauthorized:
+		return "unauthorized"
+	case CategoryClient:
+		return "client"
+	case CategoryServer:
+		return "server"
+	}
+	return "unknown"
+}
+
+// networkErrorHints are matched against the messages of errors whose chain
+// no longer reaches ErrNetwork, e.g. because a caller wrapped with %v.
+var networkErrorHints = []string{
+	"network error", "connection refused", "connection reset", "no such host", "timeout", "dial",
+}
+
+// Classify reports what kind of failure err is. Errors carrying a status
+// are classified by it, so a 504 whose body mentions a timeout is
+// CategoryServer, not CategoryNetwork.
+func Classify(err error) Category {
+	switch {
+	case err == nil:
+		return CategoryUnknown
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		// Abandoned by the caller, not lost by the network.
+		return CategoryUnknown
+	case errors.Is(err, ErrRateLimited):
+		return CategoryRateLimited
+	case errors.Is(err, ErrUnauthorized):
+		return CategoryUnauthorized
+	}
+	if status := StatusCode(err); status != 0 {
+		switch {
+		case status == http.StatusTooManyRequests:
+			return CategoryRateLimited
+		case status == http.StatusUnauthorized:
+			return CategoryUnauthorized
+		case status >= 500:
+			return CategoryServer
+		case status >= 400:
+			return CategoryClient
+		}
+		return CategoryUnknown
+	}
+
+	var netErr net.Error
+	if errors.Is(err, ErrNetwork) || errors.As(err, &netErr) {
+		return CategoryNetwork
+	}
+	msg := strings.ToLower(err.Error())
+	for _, hint := range networkErrorHints {
+		if strings.Contains(msg, hint) {
+			return CategoryNetwork
+		}
+	}
+	return CategoryUnknown
+}
+
+// IsRetryable reports whether sending the same request again later may
+// succeed: network failures, rate limiting and server errors.
+func IsRetryable(err error) bool {
+	switch Classify(err) {
+	case CategoryNetwork, CategoryRateLimited, CategoryServer:
+		return true
+	}
+	return false
+}
+
+// IsNetworkError reports whether err is a network connectivity error, the
+// case in which writes are queued offline.
+func IsNetworkError(err error) bool {
+	return Classify(err) == CategoryNetwork
+}
@@ -0,0 +1,54 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestClassify(t *testing.T) {
+	dialErr := &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connect: connection refused")}
+
+	for _, tc := range []struct {
+		name      string
+		err       error
+		want      Category
+		retryable bool
+	}{
+		{"nil", nil, CategoryUnknown, false},
+		{"network sentinel", fmt.Errorf("%w: %v", ErrNetwork, dialErr), CategoryNetwork, true},
+		{"net.Error", dialErr, CategoryNetwork, true},
+		{"network flattened with %v", fmt.Errorf("claim failed: %v", dialErr), CategoryNetwork, true},
+		{"no such host", errors.New("lookup api.example: no such host"), CategoryNetwork, true},
+		{"rate limit sentinel", ErrRateLimited, CategoryRateLimited, true},
+		{"rate limit with Retry-After", &RateLimitError{RetryAfter: 5}, CategoryRateLimited, true},
+		{"429 status", &APIError{StatusCode: 429}, CategoryRateLimited, true},
+		{"unauthorized sentinel", ErrUnauthorized, CategoryUnauthorized, false},
+		{"401 status", &APIError{StatusCode: 401}, CategoryUnauthorized, false},
+		{"400", &APIError{StatusCode: 400, Body: []byte(`{"error":"bad lane"}`)}, CategoryClient, false},
+		{"404", &APIError{StatusCode: 404}, CategoryClient, false},
+		{"500", &APIError{StatusCode: 500}, CategoryServer, true},
+		{"504 mentioning a timeout", &APIError{StatusCode: 504, Body: []byte("gateway timeout")}, CategoryServer, true},
+		{"wrapped 503", fmt.Errorf("failed to claim card c1: %w", &APIError{StatusCode: 503}), CategoryServer, true},
+		{"wrapped 422", fmt.Errorf("failed to transition: %w", &APIError{StatusCode: 422}), CategoryClient, false},
+		{"wrapped rate limit", fmt.Errorf("page 2: %w", &RateLimitError{}), CategoryRateLimited, true},
+		{"cancelled", fmt.Errorf("request aborted: %w", context.Canceled), CategoryUnknown, false},
+		{"deadline", fmt.Errorf("request aborted: %w", context.DeadlineExceeded), CategoryUnknown, false},
+		{"response too large", ErrResponseTooLarge, CategoryUnknown, false},
+		{"decode error", errors.New("invalid character '<' looking for beginning of value"), CategoryUnknown, false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := Classify(tc.err); got != tc.want {
+				t.Errorf("Classify(%v) = %v, want %v", tc.err, got, tc.want)
+			}
+			if got := IsRetryable(tc.err); got != tc.retryable {
+				t.Errorf("IsRetryable(%v) = %v, want %v", tc.err, got, tc.retryable)
+			}
+			if got, want := IsNetworkError(tc.err), tc.want == CategoryNetwork; got != want {
+				t.Errorf("IsNetworkError(%v) = %v, want %v", tc.err, got, want)
+			}
+		})
+	}
+}
@@ -2,10 +2,15 @@ package api
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
+	"net"
 	"net/http"
+	"strconv"
+	"strings"
 	"time"
 )
 
@@ -18,6 +23,117 @@ var ErrRateLimited = fmt.Errorf("rate limited. Wait and retry")
 // ErrNetwork is the sentinel for network-level failures.
 var ErrNetwork = fmt.Errorf("network error")
 
+// ErrNotSent marks network failures that happened before the request left
+// the client: the connection could not be made, so the server cannot have
+// acted on it and sending it again is safe. Errors matching it also match
+// ErrNetwork.
+var ErrNotSent = fmt.Errorf("request not sent")
+
+// ErrResponseTooLarge is returned when a response body exceeds the client's
+// maximum response size.
+var ErrResponseTooLarge = fmt.Errorf("response too large")
+
+// DefaultMaxResponseSize is the largest response body a Client reads unless
+// WithMaxResponseSize says otherwise.
+const DefaultMaxResponseSize = 64 << 20
+
+// RateLimitError is returned when the API responds with 429. It matches
+// ErrRateLimited via errors.Is and carries the server's Retry-After hint.
+type RateLimitError struct {
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	if e.RetryAfter > 0 {
+		return fmt.Sprintf("rate limited. Retry after %s", e.RetryAfter)
+	}
+	return ErrRateLimited.Error()
+}
+
+func (e *RateLimitError) Unwrap() error {
+	return ErrRateLimited
+}
+
+// APIError is returned for non-2xx responses not covered by a sentinel.
+type APIError struct {
+	StatusCode int
+	Body       []byte
+}
+
+// Error summarizes the response body: JSON bodies are shown as-is, but HTML
+// pages from gateways and other text are cut down to one line. The full body
+// stays in Body.
+func (e *APIError) Error() string {
+	return fmt.Sprintf("API error %d: %s", e.StatusCode, summarizeBody(e.Body))
+}
+
+// maxErrorSummary is the longest non-JSON error body shown in an error.
+const maxErrorSummary = 200
+
+// summarizeBody renders an error response body for a one-line message.
+func summarizeBody(body []byte) string {
+	text := strings.TrimSpace(string(body))
+	switch {
+	case text == "":
+		return "(empty response body)"
+	case json.Valid(body):
+		return text
+	case looksLikeHTML(text):
+		if title := htmlTitle(text); title != "" {
+			return "gateway error: " + title
+		}
+		return "gateway error (HTML response)"
+	}
+
+	line, rest, _ := strings.Cut(text, "\n")
+	line = strings.TrimSpace(line)
+	if len(line) > maxErrorSummary {
+		line, rest = line[:maxErrorSummary], "..."
+	}
+	if rest != "" {
+		line += " ..."
+	}
+	return line
+}
+
+func looksLikeHTML(text string) bool {
+	lower := strings.ToLower(text)
+	return strings.HasPrefix(lower, "<!doctype html") || strings.HasPrefix(lower, "<html") ||
+		strings.Contains(lower, "<body")
+}
+
+// htmlTitle returns the text of the page's <title>, or "".
+func htmlTitle(text string) string {
+	lower := strings.ToLower(text)
+	start := strings.Index(lower, "<title>")
+	if start < 0 {
+		return ""
+	}
+	start += len("<title>")
+	end := strings.Index(lower[start:], "</title>")
+	if end < 0 {
+		return ""
+	}
+	return strings.Join(strings.Fields(text[start:start+end]), " ")
+}
+
+// StatusCode returns the HTTP status carried by err, or 0 if err is not an
+// *APIError.
+func StatusCode(err error) int {
+	var apiErr *APIError
+	if errors.As(err, &apiErr) {
+		return apiErr.StatusCode
+	}
+	return 0
+}
+
+// Response is the raw result of an API call.
+type Response struct {
+	StatusCode int
+	Header     http.Header
+	Body       []byte
+}
+
 // TokenProvider is implemented by auth.TokenStore. Using an interface here
 // avoids an import cycle between the api and auth packages.
 type TokenProvider interface {
@@ -36,38 +152,227 @@ type TokenResponse struct {
 // Client is the base HTTP client for the dea Edge Function API.
 type Client struct {
 	baseURL    string
+	ctx        context.Context
 	httpClient *http.Client
 	tokens     TokenProvider
+	timings    *Timings
+	notifier   func(format string, args ...interface{})
+	reauth     func(expiredToken string) error
+	maxBody    int64
+	limiter    *rateLimiter
+	headers    http.Header
+
+	maxIdleConns    int
+	idleConnTimeout time.Duration
 }
 
-// NewClient creates a new API client.
-func NewClient(baseURL string, timeoutSeconds int, tokens TokenProvider) *Client {
-	return &Client{
+// Option configures optional Client behavior.
+type Option func(*Client)
+
+// WithTimings records the duration of every request in t.
+func WithTimings(t *Timings) Option {
+	return func(c *Client) {
+		c.timings = t
+	}
+}
+
+// WithNotifier has the client report things the user should know about,
+// such as waiting out a rate limit, through notify.
+func WithNotifier(notify func(format string, args ...interface{})) Option {
+	return func(c *Client) {
+		c.notifier = notify
+	}
+}
+
+// WithReauth has the client recover from a 401 once per request: it calls
+// reauth with the token that was rejected and, if that succeeds, repeats the
+// request with the provider's current token. reauth should refresh and save
+// the token, or return nil without refreshing if the stored token has
+// already changed.
+func WithReauth(reauth func(expiredToken string) error) Option {
+	return func(c *Client) {
+		c.reauth = reauth
+	}
+}
+
+// WithMaxResponseSize makes requests fail with ErrResponseTooLarge rather
+// than read more than n bytes of response body into memory.
+func WithMaxResponseSize(n int64) Option {
+	return func(c *Client) {
+		c.maxBody = n
+	}
+}
+
+// WithHeaders adds headers to every request the client sends. They never
+// replace headers the client sets itself, such as Authorization.
+func WithHeaders(headers map[string]string) Option {
+	return func(c *Client) {
+		if len(headers) == 0 {
+			return
+		}
+		c.headers = make(http.Header, len(headers))
+		for k, v := range headers {
+			c.headers.Set(k, v)
+		}
+	}
+}
+
+// WithContext makes ctx the context of requests that aren't given one,
+// such as Get, Post and Delete, so cancelling it aborts them mid-flight.
+func WithContext(ctx context.Context) Option {
+	return func(c *Client) {
+		if ctx != nil {
+			c.ctx = ctx
+		}
+	}
+}
+
+// WithHTTPClient sends requests through hc instead of a client built from
+// the timeout passed to NewClient, e.g. to use a stub RoundTripper in tests.
+// hc's own Timeout applies.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) {
+		if hc != nil {
+			c.httpClient = hc
+		}
+	}
+}
+
+// NewClient creates a new API client. Every request, including token
+// refresh and issue, goes through the same *http.Client.
+func NewClient(baseURL string, timeoutSeconds int, tokens TokenProvider, opts ...Option) *Client {
+	c := &Client{
 		baseURL: baseURL,
-		httpClient: &http.Client{
-			Timeout: time.Duration(timeoutSeconds) * time.Second,
-		},
-		tokens: tokens,
+		ctx:     context.Background(),
+		tokens:  tokens,
+		maxBody: DefaultMaxResponseSize,
+
+		maxIdleConns:    DefaultMaxIdleConns,
+		idleConnTimeout: DefaultIdleConnTimeout,
+	}
+	for _, opt := range opts {
+		opt(c)
+	}
+	if c.httpClient == nil {
+		c.httpClient = &http.Client{
+			Timeout:   time.Duration(timeoutSeconds) * time.Second,
+			Transport: c.newTransport(),
+		}
+	}
+	return c
+}
+
+// notify reports a message through the notifier, if one is set.
+func (c *Client) notify(format string, args ...interface{}) {
+	if c.notifier != nil {
+		c.notifier(format, args...)
+	}
+}
+
+// readBody reads a response body, failing once it passes the maximum
+// response size instead of buffering all of it.
+func (c *Client) readBody(r io.Reader) ([]byte, error) {
+	if c.maxBody <= 0 {
+		return io.ReadAll(r)
+	}
+	data, err := io.ReadAll(io.LimitReader(r, c.maxBody+1))
+	if err != nil {
+		return nil, err
+	}
+	if int64(len(data)) > c.maxBody {
+		return nil, fmt.Errorf("%w: more than %d bytes", ErrResponseTooLarge, c.maxBody)
+	}
+	return data, nil
+}
+
+// send executes req through the client's *http.Client; see sendVia.
+func (c *Client) send(req *http.Request) (*http.Response, error) {
+	return c.sendVia(c.httpClient.Do, req)
+}
+
+// sendVia executes req with do after adding any extra headers, recording
+// its duration when timing is enabled and waiting first when a rate limit
+// is set. Every request the client makes goes through here.
+func (c *Client) sendVia(do func(*http.Request) (*http.Response, error), req *http.Request) (*http.Response, error) {
+	for k, v := range c.headers {
+		if req.Header.Get(k) == "" {
+			req.Header[k] = v
+		}
 	}
+	if c.limiter != nil {
+		if err := c.limiter.wait(req.Context()); err != nil {
+			return nil, err
+		}
+	}
+	if c.timings == nil {
+		return do(req)
+	}
+	start := time.Now()
+	resp, err := do(req)
+	c.timings.record(req.Method, req.URL.Path, time.Since(start))
+	return resp, err
 }
 
 // Get performs an authenticated GET request.
 func (c *Client) Get(path string) ([]byte, error) {
-	return c.do("GET", path, nil)
+	resp, err := c.Do(c.ctx, "GET", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
 }
 
 // Post performs an authenticated POST request with a JSON body.
 func (c *Client) Post(path string, body interface{}) ([]byte, error) {
-	data, err := json.Marshal(body)
+	resp, err := c.Do(c.ctx, "POST", path, body)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		return nil, err
 	}
-	return c.do("POST", path, data)
+	return resp.Body, nil
 }
 
-// do executes an HTTP request with the workspace JWT in the Authorization header.
-func (c *Client) do(method, path string, body []byte) ([]byte, error) {
+// Delete performs an authenticated DELETE request.
+func (c *Client) Delete(path string) ([]byte, error) {
+	resp, err := c.Do(c.ctx, "DELETE", path, nil)
+	if err != nil {
+		return nil, err
+	}
+	return resp.Body, nil
+}
+
+// Do performs an authenticated request, JSON-encoding body when non-nil.
+// When the server answers with an error status, the Response is returned
+// alongside the error so callers can inspect headers such as Retry-After.
+// Cancelling ctx aborts the request with an error wrapping ctx.Err().
+func (c *Client) Do(ctx context.Context, method, path string, body interface{}) (*Response, error) {
+	var data []byte
+	if body != nil {
+		var err error
+		data, err = json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal request body: %w", err)
+		}
+	}
+	return c.do(ctx, method, path, data, nil)
+}
+
+// do executes an HTTP request with the workspace JWT in the Authorization
+// header, adding any extra headers. A 401 is retried once after reauth when
+// WithReauth is set.
+func (c *Client) do(ctx context.Context, method, path string, body []byte, header http.Header) (*Response, error) {
 	token := c.tokens.GetToken()
+	resp, err := c.doWithToken(ctx, method, path, body, header, token)
+	if !errors.Is(err, ErrUnauthorized) || c.reauth == nil {
+		return resp, err
+	}
+	if rerr := c.reauth(token); rerr != nil {
+		c.notify("could not refresh expired token: %v", rerr)
+		return resp, err
+	}
+	return c.doWithToken(ctx, method, path, body, header, c.tokens.GetToken())
+}
+
+func (c *Client) doWithToken(ctx context.Context, method, path string, body []byte, header http.Header, token string) (*Response, error) {
 	if token == "" {
 		return nil, fmt.Errorf("not authenticated. Run `dea auth login`")
 	}
@@ -79,39 +384,125 @@ func (c *Client) do(method, path string, body []byte) ([]byte, error) {
 		bodyReader = bytes.NewReader(body)
 	}
 
-	req, err := http.NewRequest(method, url, bodyReader)
+	req, err := http.NewRequestWithContext(ctx, method, url, bodyReader)
 	if err != nil {
 		return nil, fmt.Errorf("failed to create request: %w", err)
 	}
 
+	for k, v := range header {
+		req.Header[k] = v
+	}
 	req.Header.Set("Authorization", "Bearer "+token)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
 
-	resp, err := c.httpClient.Do(req)
+	httpResp, err := c.send(req)
 	if err != nil {
-		return nil, fmt.Errorf("%w: %v", ErrNetwork, err)
+		return nil, sendError(ctx, err)
 	}
-	defer resp.Body.Close()
+	defer httpResp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := c.readBody(httpResp.Body)
 	if err != nil {
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	switch resp.StatusCode {
+	resp := &Response{
+		StatusCode: httpResp.StatusCode,
+		Header:     httpResp.Header,
+		Body:       respBody,
+	}
+
+	switch httpResp.StatusCode {
 	case http.StatusUnauthorized:
-		return nil, ErrUnauthorized
+		return resp, ErrUnauthorized
 	case http.StatusTooManyRequests:
-		return nil, ErrRateLimited
+		return resp, &RateLimitError{RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After"))}
 	case http.StatusOK, http.StatusCreated, http.StatusAccepted, http.StatusNoContent:
-		return respBody, nil
+		return resp, nil
+	case http.StatusNotModified:
+		// Only sent in answer to a conditional request; see GetIfChanged.
+		return resp, nil
 	default:
-		return nil, fmt.Errorf("API error %d: %s", resp.StatusCode, string(respBody))
+		return resp, &APIError{StatusCode: httpResp.StatusCode, Body: respBody}
 	}
 }
 
+// sendError wraps an error from sending a request: ErrNetwork, unless ctx
+// was cancelled, in which case the request was abandoned on purpose and
+// must not be mistaken for being offline. Failures to connect also match
+// ErrNotSent.
+func sendError(ctx context.Context, err error) error {
+	if ctxErr := ctx.Err(); ctxErr != nil {
+		return fmt.Errorf("request aborted: %w", ctxErr)
+	}
+	wrapped := fmt.Errorf("%w: %v", ErrNetwork, err)
+	if connectFailed(err) {
+		return &notSentError{wrapped}
+	}
+	return wrapped
+}
+
+// connectFailed reports whether err came from dialing (including DNS
+// lookup) or connecting through a proxy, before any of the request was
+// written.
+func connectFailed(err error) bool {
+	var opErr *net.OpError
+	if errors.As(err, &opErr) {
+		return opErr.Op == "dial" || opErr.Op == "proxyconnect"
+	}
+	var dnsErr *net.DNSError
+	return errors.As(err, &dnsErr)
+}
+
+// notSentError is a network error that also matches ErrNotSent, without
+// changing its message.
+type notSentError struct{ err error }
+
+func (e *notSentError) Error() string {
+	return e.err.Error()
+}
+
+func (e *notSentError) Unwrap() error {
+	return e.err
+}
+
+func (e *notSentError) Is(target error) bool {
+	return target == ErrNotSent
+}
+
+// VerifyToken makes an authenticated GET to path with the current token and
+// reports whether the server accepted the token: nil if it did, even when
+// the request itself was forbidden or not found, and ErrUnauthorized if it
+// didn't. WithReauth is bypassed so a rejected token isn't quietly replaced.
+// Other errors mean the check could not be made.
+func (c *Client) VerifyToken(path string) error {
+	_, err := c.doWithToken(c.ctx, "GET", path, nil, nil, c.tokens.GetToken())
+	switch StatusCode(err) {
+	case http.StatusForbidden, http.StatusNotFound:
+		return nil
+	}
+	return err
+}
+
+// parseRetryAfter interprets a Retry-After header given either as seconds or
+// as an HTTP date. Returns 0 when absent or unparseable.
+func parseRetryAfter(v string) time.Duration {
+	if v == "" {
+		return 0
+	}
+	if secs, err := strconv.Atoi(v); err == nil && secs > 0 {
+		return time.Duration(secs) * time.Second
+	}
+	if t, err := http.ParseTime(v); err == nil {
+		if d := time.Until(t); d > 0 {
+			return d
+		}
+	}
+	return 0
+}
+
 // RefreshToken calls the token-service/refresh endpoint.
 func (c *Client) RefreshToken(currentToken string) (*TokenResponse, error) {
 	url := c.baseURL + PathTokenRefresh
@@ -121,14 +512,14 @@ func (c *Client) RefreshToken(currentToken string) (*TokenResponse, error) {
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(c.ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Authorization", "Bearer "+currentToken)
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
 		return nil, err
 	}
@@ -138,7 +529,7 @@ func (c *Client) RefreshToken(currentToken string) (*TokenResponse, error) {
 		return nil, fmt.Errorf("refresh failed: HTTP %d", resp.StatusCode)
 	}
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := c.readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
@@ -167,25 +558,25 @@ func (c *Client) IssueToken(credentials map[string]string) (*TokenResponse, erro
 		return nil, err
 	}
 
-	req, err := http.NewRequest("POST", url, bytes.NewReader(body))
+	req, err := http.NewRequestWithContext(c.ctx, "POST", url, bytes.NewReader(body))
 	if err != nil {
 		return nil, err
 	}
 	req.Header.Set("Content-Type", "application/json")
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(req)
 	if err != nil {
-		return nil, err
+		return nil, sendError(c.ctx, err)
 	}
 	defer resp.Body.Close()
 
-	respBody, err := io.ReadAll(resp.Body)
+	respBody, err := c.readBody(resp.Body)
 	if err != nil {
 		return nil, err
 	}
 
 	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusCreated {
-		return nil, fmt.Errorf("login failed: HTTP %d: %s", resp.StatusCode, string(respBody))
+		return nil, fmt.Errorf("login failed: %w", &APIError{StatusCode: resp.StatusCode, Body: respBody})
 	}
 
 	// Edge function wraps response in { data: {...} }
@@ -202,29 +593,3 @@ func (c *Client) IssueToken(credentials map[string]string) (*TokenResponse, erro
 	}
 	return &wrapper.Data, nil
 }
-
-// IsNetworkError returns true if the error is a network connectivity error.
-func IsNetworkError(err error) bool {
-	if err == nil {
-		return false
-	}
-	msg := err.Error()
-	for _, keyword := range []string{"network error", "connection refused", "no such host", "timeout", "dial"} {
-		if containsStr(msg, keyword) {
-			return true
-		}
-	}
-	return false
-}
-
-func containsStr(s, sub string) bool {
-	if len(sub) > len(s) {
-		return false
-	}
-	for i := 0; i <= len(s)-len(sub); i++ {
-		if s[i:i+len(sub)] == sub {
-			return true
-		}
-	}
-	return false
-}
@@ -0,0 +1,169 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const gateway502 = `<!DOCTYPE html>
+<html>
+<head>
+  <title>
+    502 Bad Gateway
+  </title>
+</head>
+<body><center><h1>502 Bad Gateway</h1></center><hr><center>nginx</center></body>
+</html>`
+
+func TestSummarizeBody(t *testing.T) {
+	long := strings.Repeat("x", maxErrorSummary+50)
+
+	for _, tc := range []struct {
+		name string
+		body string
+		want string
+	}{
+		{"empty", "  \n", "(empty response body)"},
+		{"json", `{"error":"card not found"}`, `{"error":"card not found"}`},
+		{"html 502", gateway502, "gateway error: 502 Bad Gateway"},
+		{"html without title", "<html><body>upstream down</body></html>", "gateway error (HTML response)"},
+		{"body fragment", "<BODY>oops</BODY>", "gateway error (HTML response)"},
+		{"plain text", "upstream connect error", "upstream connect error"},
+		{"multi-line text", "first line\nsecond line", "first line ..."},
+		{"long line", long, long[:maxErrorSummary] + " ..."},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			if got := summarizeBody([]byte(tc.body)); got != tc.want {
+				t.Errorf("summarizeBody = %q, want %q", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestHTMLTitle(t *testing.T) {
+	for _, tc := range []struct {
+		text string
+		want string
+	}{
+		{gateway502, "502 Bad Gateway"},
+		{"<HTML><TITLE>Service  Unavailable</TITLE></HTML>", "Service Unavailable"},
+		{"<html><title></title></html>", ""},
+		{"<html><title>never closed", ""},
+		{"<html><body>no title</body></html>", ""},
+	} {
+		if got := htmlTitle(tc.text); got != tc.want {
+			t.Errorf("htmlTitle(%q) = %q, want %q", tc.text, got, tc.want)
+		}
+	}
+}
+
+func TestGatewayErrorResponse(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html")
+		w.WriteHeader(http.StatusBadGateway)
+		w.Write([]byte(gateway502))
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, 5, staticToken("tok")).Get(PathCards)
+	if err == nil {
+		t.Fatal("expected an error")
+	}
+	if got, want := err.Error(), "API error 502: gateway error: 502 Bad Gateway"; got != want {
+		t.Errorf("error = %q, want %q", got, want)
+	}
+	var apiErr *APIError
+	if !errors.As(err, &apiErr) || string(apiErr.Body) != gateway502 {
+		t.Errorf("full body not kept on the APIError: %v", err)
+	}
+}
+
+// closedAddr returns the URL of a local port nothing listens on.
+func closedAddr(t *testing.T) string {
+	t.Helper()
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := l.Addr().String()
+	l.Close()
+	return "http://" + addr
+}
+
+func TestConnectFailureIsNotSent(t *testing.T) {
+	_, err := NewClient(closedAddr(t), 5, staticToken("tok")).Post(PathSignals, map[string]string{})
+	if !errors.Is(err, ErrNetwork) || !errors.Is(err, ErrNotSent) {
+		t.Fatalf("err = %v, want ErrNetwork and ErrNotSent", err)
+	}
+	if !strings.HasPrefix(err.Error(), "network error: ") {
+		t.Errorf("message changed: %q", err)
+	}
+	if got := Classify(err); got != CategoryNetwork {
+		t.Errorf("Classify = %v, want network", got)
+	}
+}
+
+func TestDroppedConnectionMayHaveBeenSent(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		// Read the request, then hang up without answering.
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+
+	_, err := NewClient(srv.URL, 5, staticToken("tok")).Post(PathSignals, map[string]string{})
+	if !errors.Is(err, ErrNetwork) {
+		t.Fatalf("err = %v, want ErrNetwork", err)
+	}
+	if errors.Is(err, ErrNotSent) {
+		t.Errorf("err = %v matches ErrNotSent, but the server received the request", err)
+	}
+}
+
+func TestConnectFailed(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"dial", &net.OpError{Op: "dial", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"proxy", &net.OpError{Op: "proxyconnect", Net: "tcp", Err: errors.New("connection refused")}, true},
+		{"dns", &net.DNSError{Err: "no such host", Name: "api.example", IsNotFound: true}, true},
+		{"read", &net.OpError{Op: "read", Net: "tcp", Err: errors.New("connection reset by peer")}, false},
+		{"write", &net.OpError{Op: "write", Net: "tcp", Err: errors.New("broken pipe")}, false},
+		{"eof", errors.New("EOF"), false},
+	} {
+		if got := connectFailed(tc.err); got != tc.want {
+			t.Errorf("%s: connectFailed = %v, want %v", tc.name, got, tc.want)
+		}
+	}
+}
+
+func TestIssueTokenHonoursContext(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		<-r.Context().Done()
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(50*time.Millisecond, cancel)
+	_, err := NewClient(srv.URL, 30, staticToken(""), WithContext(ctx)).IssueToken(map[string]string{"agent_id": "a"})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("err = %v, want context.Canceled", err)
+	}
+	if errors.Is(err, ErrNetwork) {
+		t.Errorf("err = %v is a network error; an aborted login must not be retried", err)
+	}
+}
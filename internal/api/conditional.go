@@ -0,0 +1,40 @@
+package api
+
+import (
+	"net/http"
+)
+
+// Validators are the cache validators of a previous response, sent back on
+// the next request so the server can answer 304 Not Modified.
+type Validators struct {
+	ETag         string
+	LastModified string
+}
+
+// ValidatorsFrom returns the ETag and Last-Modified headers of resp.
+func ValidatorsFrom(resp *Response) Validators {
+	return Validators{
+		ETag:         resp.Header.Get("ETag"),
+		LastModified: resp.Header.Get("Last-Modified"),
+	}
+}
+
+// GetIfChanged performs a GET carrying If-None-Match and If-Modified-Since
+// from prev. When the server answers 304 it returns modified=false and the
+// caller should keep using what it already has. A zero prev makes this an
+// ordinary GET.
+func (c *Client) GetIfChanged(path string, prev Validators) (resp *Response, modified bool, err error) {
+	header := http.Header{}
+	if prev.ETag != "" {
+		header.Set("If-None-Match", prev.ETag)
+	}
+	if prev.LastModified != "" {
+		header.Set("If-Modified-Since", prev.LastModified)
+	}
+
+	resp, err = c.do(c.ctx, "GET", path, nil, header)
+	if err != nil {
+		return resp, false, err
+	}
+	return resp, resp.StatusCode != http.StatusNotModified, nil
+}
@@ -0,0 +1,146 @@
+package api
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+)
+
+// Device-flow poll outcomes other than success, following RFC 8628.
+var (
+	// ErrAuthorizationPending means the user hasn't approved the code yet.
+	ErrAuthorizationPending = errors.New("authorization pending")
+
+	// ErrSlowDown means the client is polling too fast and should wait
+	// longer between polls.
+	ErrSlowDown = errors.New("polling too fast")
+
+	// ErrAccessDenied means the user declined the login.
+	ErrAccessDenied = errors.New("login was denied")
+
+	// ErrDeviceCodeExpired means the code expired before it was approved.
+	ErrDeviceCodeExpired = errors.New("device code expired")
+)
+
+// DeviceCode is returned when a device-flow login starts. The user visits
+// VerificationURI and enters UserCode while the CLI polls with DeviceCode.
+type DeviceCode struct {
+	DeviceCode              string `json:"device_code"`
+	UserCode                string `json:"user_code"`
+	VerificationURI         string `json:"verification_uri"`
+	VerificationURIComplete string `json:"verification_uri_complete"`
+	ExpiresIn               int    `json:"expires_in"`
+	Interval                int    `json:"interval"`
+}
+
+// RequestDeviceCode starts an SSO device-flow login.
+func (c *Client) RequestDeviceCode() (*DeviceCode, error) {
+	respBody, status, err := c.postUnauthenticated(PathDeviceCode, map[string]string{})
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		return nil, fmt.Errorf("device login failed: %w", &APIError{StatusCode: status, Body: respBody})
+	}
+
+	var wrapper struct {
+		Data *DeviceCode `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &wrapper); err == nil && wrapper.Data != nil {
+		return wrapper.Data, nil
+	}
+	var code DeviceCode
+	if err := json.Unmarshal(respBody, &code); err != nil {
+		return nil, fmt.Errorf("unexpected device code response: %w", err)
+	}
+	if code.DeviceCode == "" {
+		return nil, fmt.Errorf("unexpected device code response: missing device_code")
+	}
+	return &code, nil
+}
+
+// PollDeviceToken asks whether deviceCode has been approved. Until it has,
+// it returns ErrAuthorizationPending or ErrSlowDown; ErrAccessDenied and
+// ErrDeviceCodeExpired are final.
+func (c *Client) PollDeviceToken(deviceCode string) (*TokenResponse, error) {
+	respBody, status, err := c.postUnauthenticated(PathDeviceToken, map[string]string{
+		"device_code": deviceCode,
+		"grant_type":  "urn:ietf:params:oauth:grant-type:device_code",
+	})
+	if err != nil {
+		return nil, err
+	}
+	if status != http.StatusOK && status != http.StatusCreated {
+		switch deviceErrorCode(respBody) {
+		case "authorization_pending":
+			return nil, ErrAuthorizationPending
+		case "slow_down":
+			return nil, ErrSlowDown
+		case "access_denied":
+			return nil, ErrAccessDenied
+		case "expired_token":
+			return nil, ErrDeviceCodeExpired
+		}
+		return nil, fmt.Errorf("device login failed: %w", &APIError{StatusCode: status, Body: respBody})
+	}
+
+	var wrapper struct {
+		Data *TokenResponse `json:"data"`
+	}
+	if err := json.Unmarshal(respBody, &wrapper); err == nil && wrapper.Data != nil {
+		return wrapper.Data, nil
+	}
+	var tokenResp TokenResponse
+	if err := json.Unmarshal(respBody, &tokenResp); err != nil {
+		return nil, fmt.Errorf("unexpected token response: %w", err)
+	}
+	return &tokenResp, nil
+}
+
+// postUnauthenticated POSTs body as JSON without a token and returns the
+// response body and status. Network failures wrap ErrNetwork.
+func (c *Client) postUnauthenticated(path string, body interface{}) ([]byte, int, error) {
+	data, err := json.Marshal(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	req, err := http.NewRequestWithContext(c.ctx, "POST", c.baseURL+path, bytes.NewReader(data))
+	if err != nil {
+		return nil, 0, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.send(req)
+	if err != nil {
+		return nil, 0, sendError(c.ctx, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := c.readBody(resp.Body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return respBody, resp.StatusCode, nil
+}
+
+// deviceErrorCode extracts the OAuth error code from a device-flow error
+// body, given either as {"error": "code"} or {"error": {"code": "code"}}.
+func deviceErrorCode(body []byte) string {
+	var flat struct {
+		Error string `json:"error"`
+	}
+	if json.Unmarshal(body, &flat) == nil && flat.Error != "" {
+		return flat.Error
+	}
+	var nested struct {
+		Error struct {
+			Code string `json:"code"`
+		} `json:"error"`
+	}
+	if json.Unmarshal(body, &nested) == nil {
+		return nested.Error.Code
+	}
+	return ""
+}
@@ -1,5 +1,7 @@
 package api
 
+import "net/url"
+
 const (
 	// PathCards is the base path for card operations.
 	PathCards = "/workspace-api/api/cards"
@@ -30,6 +32,12 @@ const (
 
 	// PathTokenRevoke is the token-service revoke endpoint.
 	PathTokenRevoke = "/token-service/revoke"
+
+	// PathDeviceCode starts an SSO device-flow login (no JWT required).
+	PathDeviceCode = "/token-service/device/code"
+
+	// PathDeviceToken exchanges an authorized device code for a token.
+	PathDeviceToken = "/token-service/device/token"
 )
 
 // CardPath returns the path for a specific card.
@@ -37,6 +45,11 @@ func CardPath(cardID string) string {
 	return PathCards + "/" + cardID
 }
 
+// ArtifactPath returns the path for a specific registered artifact.
+func ArtifactPath(artifactID string) string {
+	return PathArtifacts + "/" + url.PathEscape(artifactID)
+}
+
 // CardTransitionPath returns the path for transitioning a card.
 func CardTransitionPath(cardID string) string {
 	return PathCards + "/" + cardID + "/transition"
@@ -52,7 +65,42 @@ func CardContextPath(cardID string) string {
 	return PathCards + "/" + cardID + "/context"
 }
 
+// CardAssignPath returns the path for assigning a card to an agent.
+func CardAssignPath(cardID string) string {
+	return PathCards + "/" + cardID + "/assign"
+}
+
+// CardCommentsPath returns the path for a card's comments.
+func CardCommentsPath(cardID string) string {
+	return PathCards + "/" + cardID + "/comments"
+}
+
+// CardHistoryPath returns the path for a card's transition history.
+func CardHistoryPath(cardID string) string {
+	return PathCards + "/" + cardID + "/history"
+}
+
+// CardLinksPath returns the path for a card's relationships to other cards.
+func CardLinksPath(cardID string) string {
+	return PathCards + "/" + cardID + "/links"
+}
+
+// VaultPath returns the path for a specific vault entry.
+func VaultPath(key string) string {
+	return PathVault + "/" + url.PathEscape(key)
+}
+
 // AutomationRunPath returns the path for running an automation.
 func AutomationRunPath(automationID string) string {
 	return PathAutomations + "/" + automationID + "/run"
 }
+
+// AutomationRunStatusPath returns the path for a single automation run.
+func AutomationRunStatusPath(automationID, runID string) string {
+	return PathAutomations + "/" + automationID + "/runs/" + runID
+}
+
+// AutomationRunLogsPath returns the path for streaming a run's logs.
+func AutomationRunLogsPath(automationID, runID string) string {
+	return PathAutomations + "/" + automationID + "/runs/" + runID + "/logs"
+}
@@ -0,0 +1,124 @@
+package api
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"strings"
+	"time"
+)
+
+const (
+	// MaxPages bounds how many pages GetAll follows in case the server
+	// never stops returning a next page.
+	MaxPages = 100
+
+	// MaxRateLimitWait caps how long GetAll waits out a 429 before giving up.
+	MaxRateLimitWait = 30 * time.Second
+)
+
+// GetAll performs a GET and follows server-driven pagination, returning
+// the body of every page in order. The next page is taken from an
+// X-Next-Cursor header (sent back as ?cursor=) or a Link header with
+// rel="next". A single 429 per page is waited out when its Retry-After is
+// at most MaxRateLimitWait.
+func (c *Client) GetAll(path string) ([][]byte, error) {
+	var pages [][]byte
+	for n := 0; n < MaxPages; n++ {
+		resp, err := c.getPage(path)
+		if err != nil {
+			return nil, err
+		}
+		pages = append(pages, resp.Body)
+
+		next, err := nextPagePath(path, resp, c.baseURL)
+		if err != nil {
+			return nil, err
+		}
+		if next == "" {
+			break
+		}
+		path = next
+	}
+	return pages, nil
+}
+
+// getPage performs one GET, retrying once after a short 429.
+func (c *Client) getPage(path string) (*Response, error) {
+	resp, err := c.Do(c.ctx, "GET", path, nil)
+
+	var rl *RateLimitError
+	if errors.As(err, &rl) && rl.RetryAfter > 0 && rl.RetryAfter <= MaxRateLimitWait {
+		c.notify("Rate limited; retrying in %s...", rl.RetryAfter)
+		time.Sleep(rl.RetryAfter)
+		resp, err = c.Do(c.ctx, "GET", path, nil)
+	}
+	return resp, err
+}
+
+// nextPagePath returns the path of the page after resp, or "" on the last
+// page.
+func nextPagePath(path string, resp *Response, baseURL string) (string, error) {
+	if cursor := resp.Header.Get("X-Next-Cursor"); cursor != "" {
+		u, err := url.Parse(path)
+		if err != nil {
+			return "", fmt.Errorf("invalid path %q: %w", path, err)
+		}
+		q := u.Query()
+		q.Set("cursor", cursor)
+		u.RawQuery = q.Encode()
+		return u.String(), nil
+	}
+
+	link := linkNext(resp.Header.Values("Link"))
+	if link == "" {
+		return "", nil
+	}
+	// Links may be absolute, host-relative or relative to the current page.
+	if strings.HasPrefix(link, baseURL) {
+		return strings.TrimPrefix(link, baseURL), nil
+	}
+	base, err := url.Parse(path)
+	if err != nil {
+		return "", fmt.Errorf("invalid path %q: %w", path, err)
+	}
+	ref, err := url.Parse(link)
+	if err != nil {
+		return "", fmt.Errorf("invalid next link %q: %w", link, err)
+	}
+	if ref.IsAbs() {
+		return "", fmt.Errorf("next link %q is not on %s", link, baseURL)
+	}
+	return base.ResolveReference(ref).String(), nil
+}
+
+// linkNext extracts the rel="next" target from Link header values such as
+// `<https://host/path?page=2>; rel="next", <...>; rel="prev"`.
+func linkNext(values []string) string {
+	for _, v := range values {
+		for _, part := range strings.Split(v, ",") {
+			target, params, ok := strings.Cut(strings.TrimSpace(part), ";")
+			if !ok {
+				continue
+			}
+			for _, p := range strings.Split(params, ";") {
+				key, val, _ := strings.Cut(strings.TrimSpace(p), "=")
+				if strings.EqualFold(key, "rel") && containsNext(strings.Trim(val, `"`)) {
+					return strings.Trim(strings.TrimSpace(target), "<>")
+				}
+			}
+		}
+	}
+	return ""
+}
+
+// containsNext reports whether a rel value, which may list several
+// space-separated relations, includes "next".
+func containsNext(rel string) bool {
+	for _, r := range strings.Fields(rel) {
+		if strings.EqualFold(r, "next") {
+			return true
+		}
+	}
+	return false
+}
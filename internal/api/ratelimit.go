@@ -0,0 +1,71 @@
+package api
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// rateLimiter is a token bucket: it holds up to burst tokens, refilled at
+// rate per second, and each request takes one, waiting for it if the bucket
+// is empty.
+type rateLimiter struct {
+	mu     sync.Mutex
+	rate   float64
+	burst  float64
+	tokens float64
+	last   time.Time
+}
+
+// newRateLimiter allows rps requests per second on average, with bursts of
+// up to one second's worth (at least one request).
+func newRateLimiter(rps float64) *rateLimiter {
+	burst := math.Max(1, math.Floor(rps))
+	return &rateLimiter{rate: rps, burst: burst, tokens: burst, last: time.Now()}
+}
+
+// wait blocks until a request may be sent, or returns ctx.Err() if ctx is
+// cancelled first. A cancelled wait gives its token back.
+func (l *rateLimiter) wait(ctx context.Context) error {
+	l.mu.Lock()
+	now := time.Now()
+	l.tokens = math.Min(l.burst, l.tokens+now.Sub(l.last).Seconds()*l.rate)
+	l.last = now
+
+	// Take the token now, even if that leaves the bucket in debt, so
+	// concurrent callers queue up behind each other rather than all
+	// waking at once.
+	l.tokens--
+	var delay time.Duration
+	if l.tokens < 0 {
+		delay = time.Duration(-l.tokens / l.rate * float64(time.Second))
+	}
+	l.mu.Unlock()
+
+	if delay <= 0 {
+		return nil
+	}
+	t := time.NewTimer(delay)
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return nil
+	case <-ctx.Done():
+		l.mu.Lock()
+		l.tokens++
+		l.mu.Unlock()
+		return ctx.Err()
+	}
+}
+
+// WithRateLimit paces requests to at most rps per second on average,
+// blocking briefly before a request instead of letting the server answer
+// 429. Zero or negative rps leaves requests unpaced.
+func WithRateLimit(rps float64) Option {
+	return func(c *Client) {
+		if rps > 0 {
+			c.limiter = newRateLimiter(rps)
+		}
+	}
+}
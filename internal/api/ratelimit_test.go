@@ -0,0 +1,79 @@
+package api
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+func TestRateLimiterPaces(t *testing.T) {
+	l := newRateLimiter(20)
+	start := time.Now()
+	// The first 20 go at once; the next 5 wait 50ms each.
+	for i := 0; i < 25; i++ {
+		if err := l.wait(context.Background()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if elapsed := time.Since(start); elapsed < 200*time.Millisecond {
+		t.Errorf("25 requests at 20/s took %v, want at least 200ms", elapsed)
+	}
+}
+
+func TestRateLimiterWaitCancelled(t *testing.T) {
+	l := newRateLimiter(0.2)
+	if err := l.wait(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+	start := time.Now()
+	err := l.wait(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Errorf("err = %v, want context.DeadlineExceeded", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("cancelled wait took %v, want it cut short", elapsed)
+	}
+
+	// The abandoned wait gave its token back: the bucket is one request in
+	// debt (the first), not two.
+	l.mu.Lock()
+	tokens := l.tokens
+	l.mu.Unlock()
+	if tokens < -0.1 || tokens > 0.1 {
+		t.Errorf("tokens = %v after a cancelled wait, want about 0", tokens)
+	}
+}
+
+func TestClientRateLimitHonoursContext(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte(`{}`))
+	}))
+	defer srv.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	c := NewClient(srv.URL, 5, staticToken("tok"), WithRateLimit(0.2), WithContext(ctx))
+	if _, err := c.Get(PathCards); err != nil {
+		t.Fatal(err)
+	}
+
+	time.AfterFunc(50*time.Millisecond, cancel)
+	start := time.Now()
+	_, err := c.Get(PathCards)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("Ctrl-C took %v to abort a paced request, want it cut short", elapsed)
+	}
+	if requests != 1 {
+		t.Errorf("server saw %d requests, want only the first", requests)
+	}
+}
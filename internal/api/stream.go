@@ -0,0 +1,66 @@
+package api
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+)
+
+// ErrStreamingUnsupported is returned by Stream when the server has no
+// streaming response for the path, so callers can fall back to polling.
+var ErrStreamingUnsupported = fmt.Errorf("streaming not supported by server")
+
+// Stream opens an authenticated GET whose body is read incrementally, such
+// as server-sent events or a chunked log. The client timeout does not apply;
+// cancel ctx to stop. The caller must close the returned body.
+func (c *Client) Stream(ctx context.Context, path string) (io.ReadCloser, error) {
+	token := c.tokens.GetToken()
+	if token == "" {
+		return nil, fmt.Errorf("not authenticated. Run `dea auth login`")
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "GET", c.baseURL+path, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	req.Header.Set("Authorization", "Bearer "+token)
+	req.Header.Set("Accept", "text/event-stream, text/plain")
+
+	// Go straight to the transport: the client's overall timeout would cut
+	// off a stream that legitimately stays open for as long as the run
+	// lasts.
+	transport := c.httpClient.Transport
+	if transport == nil {
+		transport = http.DefaultTransport
+	}
+	httpResp, err := c.sendVia(transport.RoundTrip, req)
+	if err != nil {
+		return nil, sendError(ctx, err)
+	}
+
+	switch httpResp.StatusCode {
+	case http.StatusOK:
+		// A JSON answer means the endpoint exists but does not stream.
+		if ct, _, _ := mime.ParseMediaType(httpResp.Header.Get("Content-Type")); ct == "application/json" {
+			httpResp.Body.Close()
+			return nil, ErrStreamingUnsupported
+		}
+		return httpResp.Body, nil
+	case http.StatusNotFound, http.StatusMethodNotAllowed, http.StatusNotAcceptable, http.StatusNotImplemented:
+		httpResp.Body.Close()
+		return nil, ErrStreamingUnsupported
+	}
+
+	defer httpResp.Body.Close()
+	body, _ := c.readBody(httpResp.Body)
+	switch httpResp.StatusCode {
+	case http.StatusUnauthorized:
+		return nil, ErrUnauthorized
+	case http.StatusTooManyRequests:
+		return nil, &RateLimitError{RetryAfter: parseRetryAfter(httpResp.Header.Get("Retry-After"))}
+	default:
+		return nil, &APIError{StatusCode: httpResp.StatusCode, Body: body}
+	}
+}
@@ -0,0 +1,72 @@
+package api
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type staticToken string
+
+func (t staticToken) GetToken() string { return string(t) }
+
+func TestStreamSendsConfiguredHeaders(t *testing.T) {
+	var got http.Header
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+		w.Header().Set("Content-Type", "text/event-stream")
+		io.WriteString(w, "data: hello\n\n")
+	}))
+	defer srv.Close()
+
+	timings := NewTimings(io.Discard)
+	c := NewClient(srv.URL, 1, staticToken("tok"),
+		WithHeaders(map[string]string{"X-Gateway-Key": "secret", "Authorization": "ignored"}),
+		WithTimings(timings))
+
+	body, err := c.Stream(context.Background(), "/runs/1/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	if _, err := io.ReadAll(body); err != nil {
+		t.Fatal(err)
+	}
+
+	if got.Get("X-Gateway-Key") != "secret" {
+		t.Errorf("X-Gateway-Key = %q, want secret", got.Get("X-Gateway-Key"))
+	}
+	if got.Get("Authorization") != "Bearer tok" {
+		t.Errorf("Authorization = %q, want the token", got.Get("Authorization"))
+	}
+	if n, _, _, _ := timings.Summary(); n != 1 {
+		t.Errorf("recorded %d timings, want 1", n)
+	}
+}
+
+func TestStreamOutlivesClientTimeout(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/plain")
+		w.(http.Flusher).Flush()
+		time.Sleep(1500 * time.Millisecond)
+		io.WriteString(w, "done\n")
+	}))
+	defer srv.Close()
+
+	c := NewClient(srv.URL, 1, staticToken("tok"))
+	body, err := c.Stream(context.Background(), "/runs/1/logs")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer body.Close()
+	data, err := io.ReadAll(body)
+	if err != nil {
+		t.Fatalf("stream cut off: %v", err)
+	}
+	if string(data) != "done\n" {
+		t.Errorf("body = %q", data)
+	}
+}
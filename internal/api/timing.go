@@ -0,0 +1,52 @@
+package api
+
+import (
+	"fmt"
+	"io"
+	"sync"
+	"time"
+)
+
+// Timings records the wall-clock duration of each API call, echoing every
+// call to w as it completes and keeping min/avg/max for a final summary.
+type Timings struct {
+	mu    sync.Mutex
+	w     io.Writer
+	count int
+	min   time.Duration
+	max   time.Duration
+	total time.Duration
+}
+
+// NewTimings creates a Timings that reports each call to w.
+func NewTimings(w io.Writer) *Timings {
+	return &Timings{w: w}
+}
+
+func (t *Timings) record(method, path string, d time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.count == 0 || d < t.min {
+		t.min = d
+	}
+	if d > t.max {
+		t.max = d
+	}
+	t.total += d
+	t.count++
+
+	fmt.Fprintf(t.w, "[timing] %s %s %s\n", method, path, d.Round(time.Millisecond))
+}
+
+// Summary returns the number of recorded calls and their min, average, and
+// max durations.
+func (t *Timings) Summary() (count int, min, avg, max time.Duration) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.count == 0 {
+		return 0, 0, 0, 0
+	}
+	return t.count, t.min, t.total / time.Duration(t.count), t.max
+}
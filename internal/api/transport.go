@@ -0,0 +1,42 @@
+package api
+
+import (
+	"net/http"
+	"time"
+)
+
+const (
+	// DefaultMaxIdleConns is how many idle connections to the endpoint a
+	// Client keeps open for reuse. net/http keeps only two per host, so
+	// concurrent pushes would otherwise reconnect (and redo TLS) for every
+	// request beyond the second.
+	DefaultMaxIdleConns = 16
+
+	// DefaultIdleConnTimeout is how long an idle connection is kept.
+	DefaultIdleConnTimeout = 90 * time.Second
+)
+
+// WithConnectionPool sets how many idle connections the client keeps for
+// reuse and for how long. Zero values keep the defaults. It has no effect
+// with WithHTTPClient, whose transport is used as given.
+func WithConnectionPool(maxIdle int, idleTimeout time.Duration) Option {
+	return func(c *Client) {
+		if maxIdle > 0 {
+			c.maxIdleConns = maxIdle
+		}
+		if idleTimeout > 0 {
+			c.idleConnTimeout = idleTimeout
+		}
+	}
+}
+
+// newTransport returns a copy of http.DefaultTransport with the client's
+// pool settings. Every request goes to one host, so the per-host idle limit
+// is raised to match the overall one.
+func (c *Client) newTransport() *http.Transport {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.MaxIdleConns = c.maxIdleConns
+	t.MaxIdleConnsPerHost = c.maxIdleConns
+	t.IdleConnTimeout = c.idleConnTimeout
+	return t
+}
@@ -0,0 +1,21 @@
+package auth
+
+import "time"
+
+// Clock is where token expiry checks and the refresher get the time and
+// wait, so tests can drive them without real sleeps.
+type Clock interface {
+	Now() time.Time
+
+	// After returns a channel that receives once d has passed.
+	After(d time.Duration) <-chan time.Time
+}
+
+// SystemClock is the real clock, used when a TokenStore has no Clock set.
+var SystemClock Clock = systemClock{}
+
+type systemClock struct{}
+
+func (systemClock) Now() time.Time { return time.Now() }
+
+func (systemClock) After(d time.Duration) <-chan time.Time { return time.After(d) }
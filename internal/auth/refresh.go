@@ -1,51 +1,103 @@
 package auth
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"time"
 )
 
+// Warnf reports non-fatal token problems such as a failed background refresh
+// or a corrupt token file. It writes to stderr by default; the CLI replaces
+// it so these messages follow --log-format.
+var Warnf = func(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+// RefreshWindow is how long before expiry a token becomes eligible for refresh.
+const RefreshWindow = 4 * time.Hour
+
 // RefreshFunc is a function that refreshes a workspace token given the current
 // raw JWT. Returns the new TokenData on success.
 // Implemented as a function type to avoid import cycles between auth and api.
 type RefreshFunc func(currentToken string) (*TokenData, error)
 
+// tokenRecheckInterval bounds how long the refresher sleeps before
+// re-reading the token file, so a token replaced by another process (e.g.
+// `dea auth login`) is picked up without waiting for the old expiry.
+const tokenRecheckInterval = 5 * time.Minute
+
+// refreshRetryDelay is the wait after a failed refresh before trying again.
+const refreshRetryDelay = 5 * time.Minute
+
 // StartAutoRefresh starts a background goroutine that refreshes the token at
 // the 20hr mark (4hr before a 24hr token expiry). Call this from main() after
-// successful authentication.
+// successful authentication. The returned func stops the goroutine.
 //
-// If refresh fails: logs to stderr but does not exit — the CLI continues with
+// If refresh fails: reports it via Warnf but does not exit — the CLI continues with
 // the existing token until expiry.
-func StartAutoRefresh(store *TokenStore, refresh RefreshFunc) {
-	go func() {
-		for {
-			token := store.Load()
-			if token == nil {
-				time.Sleep(5 * time.Minute)
-				continue
-			}
+func StartAutoRefresh(store *TokenStore, refresh RefreshFunc) (stop func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	go RunAutoRefresh(ctx, store, refresh, nil)
+	return cancel
+}
 
-			expiresAt := token.ExpiresAt
-			// Refresh 4hr before expiry (at ~20hr mark for 24hr tokens).
-			refreshAt := expiresAt.Add(-4 * time.Hour)
+// RunAutoRefresh is the loop behind StartAutoRefresh. It blocks until ctx is
+// cancelled, calling onRefresh (if non-nil) after each token it saves. It
+// reads the time and waits through the store's Clock.
+func RunAutoRefresh(ctx context.Context, store *TokenStore, refresh RefreshFunc, onRefresh func(*TokenData)) {
+	clock := store.clock()
+	for {
+		token := store.Load()
+		if token == nil {
+			if !sleepCtx(ctx, clock, tokenRecheckInterval) {
+				return
+			}
+			continue
+		}
 
-			now := time.Now()
-			if now.Before(refreshAt) {
-				time.Sleep(refreshAt.Sub(now))
+		// Refresh 4hr before expiry (at ~20hr mark for 24hr tokens). Wake
+		// early to re-read the token in case it was replaced.
+		if wait := token.ExpiresAt.Add(-RefreshWindow).Sub(clock.Now()); wait > 0 {
+			if wait > tokenRecheckInterval {
+				wait = tokenRecheckInterval
+			}
+			if !sleepCtx(ctx, clock, wait) {
+				return
 			}
+			continue
+		}
 
-			// Perform refresh.
-			newToken, err := refresh(token.WorkspaceToken)
-			if err != nil {
-				fmt.Fprintf(os.Stderr, "token refresh failed: %v\n", err)
-				time.Sleep(5 * time.Minute) // retry in 5 minutes
-				continue
+		// Perform refresh.
+		newToken, err := refresh(token.WorkspaceToken)
+		if err != nil {
+			Warnf("token refresh failed: %v", err)
+			if !sleepCtx(ctx, clock, refreshRetryDelay) {
+				return
 			}
+			continue
+		}
 
-			if err := store.Save(newToken); err != nil {
-				fmt.Fprintf(os.Stderr, "failed to save refreshed token: %v\n", err)
+		if err := store.Save(newToken); err != nil {
+			Warnf("failed to save refreshed token: %v", err)
+			if !sleepCtx(ctx, clock, refreshRetryDelay) {
+				return
 			}
+			continue
 		}
-	}()
+		if onRefresh != nil {
+			onRefresh(newToken)
+		}
+	}
+}
+
+// sleepCtx waits for d on clock and reports whether it did so without ctx
+// being cancelled.
+func sleepCtx(ctx context.Context, clock Clock, d time.Duration) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	case <-clock.After(d):
+		return true
+	}
 }
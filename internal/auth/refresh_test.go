@@ -0,0 +1,143 @@
+package auth
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeClock advances its time by d whenever something waits on it, so the
+// refresher runs through hours of waiting instantly.
+type fakeClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+func (c *fakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+func (c *fakeClock) After(d time.Duration) <-chan time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+	ch := make(chan time.Time, 1)
+	ch <- c.now
+	return ch
+}
+
+var issuedAt = time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+
+// newTestStore returns a store in a temp dir holding a 24h token issued at
+// issuedAt, driven by a fake clock set to issuedAt.
+func newTestStore(t *testing.T) (*TokenStore, *fakeClock) {
+	t.Helper()
+	clock := &fakeClock{now: issuedAt}
+	store := &TokenStore{path: filepath.Join(t.TempDir(), "tokens.json"), Clock: clock}
+	if err := store.Save(&TokenData{WorkspaceToken: "old", ExpiresAt: issuedAt.Add(24 * time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	return store, clock
+}
+
+// runRefresher runs RunAutoRefresh until it has saved one token, returning
+// the clock time of every refresh attempt.
+func runRefresher(t *testing.T, store *TokenStore, clock *fakeClock, fail int) []time.Duration {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	var attempts []time.Duration
+	refresh := func(current string) (*TokenData, error) {
+		if current != "old" {
+			t.Errorf("refreshed with token %q, want old", current)
+		}
+		attempts = append(attempts, clock.Now().Sub(issuedAt))
+		if len(attempts) <= fail {
+			return nil, errors.New("token service unavailable")
+		}
+		return &TokenData{WorkspaceToken: "new", ExpiresAt: clock.Now().Add(24 * time.Hour)}, nil
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		RunAutoRefresh(ctx, store, refresh, func(*TokenData) { cancel() })
+	}()
+	select {
+	case <-done:
+	case <-time.After(5 * time.Second):
+		cancel()
+		t.Fatal("refresher did not finish")
+	}
+	return attempts
+}
+
+func TestAutoRefreshAtTwentyHours(t *testing.T) {
+	store, clock := newTestStore(t)
+	old := Warnf
+	Warnf = func(string, ...interface{}) {}
+	defer func() { Warnf = old }()
+
+	attempts := runRefresher(t, store, clock, 0)
+	if len(attempts) == 0 || attempts[0] != 20*time.Hour {
+		t.Fatalf("refresh attempts at %v, want the first at 20h", attempts)
+	}
+	if got := store.GetToken(); got != "new" {
+		t.Errorf("stored token = %q, want new", got)
+	}
+}
+
+func TestAutoRefreshBacksOffOnFailure(t *testing.T) {
+	store, clock := newTestStore(t)
+	var warnings int
+	old := Warnf
+	Warnf = func(string, ...interface{}) { warnings++ }
+	defer func() { Warnf = old }()
+
+	attempts := runRefresher(t, store, clock, 2)
+	want := []time.Duration{20 * time.Hour, 20*time.Hour + refreshRetryDelay, 20*time.Hour + 2*refreshRetryDelay}
+	if len(attempts) < len(want) {
+		t.Fatalf("refresh attempts at %v, want %v", attempts, want)
+	}
+	for i, w := range want {
+		if attempts[i] != w {
+			t.Errorf("attempt %d at %v, want %v", i+1, attempts[i], w)
+		}
+	}
+	if warnings != 2 {
+		t.Errorf("warned %d times, want 2", warnings)
+	}
+	if got := store.GetToken(); got != "new" {
+		t.Errorf("stored token = %q, want new", got)
+	}
+}
+
+func TestStoreExpiryUsesClock(t *testing.T) {
+	store, clock := newTestStore(t)
+	token := store.Load()
+
+	for _, tc := range []struct {
+		at           time.Duration
+		needsRefresh bool
+		expired      bool
+	}{
+		{0, false, false},
+		{20*time.Hour - time.Second, false, false},
+		{20 * time.Hour, true, false},
+		{24 * time.Hour, true, true},
+	} {
+		clock.now = issuedAt.Add(tc.at)
+		if got := store.NeedsRefresh(token); got != tc.needsRefresh {
+			t.Errorf("at %v: NeedsRefresh = %v, want %v", tc.at, got, tc.needsRefresh)
+		}
+		if got := store.Expired(token); got != tc.expired {
+			t.Errorf("at %v: Expired = %v, want %v", tc.at, got, tc.expired)
+		}
+	}
+}
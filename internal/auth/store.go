@@ -2,13 +2,21 @@ package auth
 
 import (
 	"encoding/json"
+	"errors"
+	"fmt"
 	"os"
+	"path/filepath"
 	"sync"
 	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/config"
+	"github.com/dea-exmachina/dea-cli/internal/fileutil"
 )
 
+// ErrCorruptToken is returned by LoadErr when the token file exists but
+// cannot be parsed.
+var ErrCorruptToken = errors.New("token file is corrupt")
+
 // TokenData is the structure stored in ~/.dea/tokens.json.
 type TokenData struct {
 	WorkspaceToken string    `json:"workspace_token"`
@@ -19,11 +27,40 @@ type TokenData struct {
 	Endpoint       string    `json:"endpoint"`
 }
 
+// NeedsRefreshAt reports whether the token is within RefreshWindow of expiry
+// at now.
+func (t *TokenData) NeedsRefreshAt(now time.Time) bool {
+	return t.ExpiresAt.Sub(now) <= RefreshWindow
+}
+
 // TokenStore manages reading and writing the token from disk.
 // It implements api.TokenProvider via the GetToken() method.
 type TokenStore struct {
 	mu   sync.RWMutex
 	path string
+
+	// Clock drives the refresher and expiry checks for this store. Nil
+	// means SystemClock.
+	Clock Clock
+}
+
+// clock returns the store's Clock, defaulting to SystemClock.
+func (s *TokenStore) clock() Clock {
+	if s.Clock == nil {
+		return SystemClock
+	}
+	return s.Clock
+}
+
+// NeedsRefresh reports whether token is within RefreshWindow of expiry by
+// the store's Clock.
+func (s *TokenStore) NeedsRefresh(token *TokenData) bool {
+	return token.NeedsRefreshAt(s.clock().Now())
+}
+
+// Expired reports whether token has expired by the store's Clock.
+func (s *TokenStore) Expired(token *TokenData) bool {
+	return !s.clock().Now().Before(token.ExpiresAt)
 }
 
 // NewTokenStore creates a TokenStore pointing at ~/.dea/tokens.json.
@@ -41,29 +78,65 @@ func (s *TokenStore) GetToken() string {
 	return token.WorkspaceToken
 }
 
-// Load reads the token from disk. Returns nil if none exists.
+// Load reads the token from disk. Returns nil if none exists. A corrupt
+// token file (e.g. truncated by an interrupted write) is moved aside to
+// tokens.json.corrupt with a warning, rather than silently reading as
+// "not authenticated".
 func (s *TokenStore) Load() *TokenData {
+	token, err := s.LoadErr()
+	if errors.Is(err, ErrCorruptToken) {
+		s.quarantine(err)
+	}
+	return token
+}
+
+// LoadErr reads the token from disk. Returns (nil, nil) if no token file
+// exists and an error wrapping ErrCorruptToken if it cannot be parsed.
+func (s *TokenStore) LoadErr() (*TokenData, error) {
 	s.mu.RLock()
 	defer s.mu.RUnlock()
 
 	data, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
 	if err != nil {
-		return nil
+		return nil, err
 	}
 
 	var token TokenData
 	if err := json.Unmarshal(data, &token); err != nil {
-		return nil
+		return nil, fmt.Errorf("%w: %v", ErrCorruptToken, err)
 	}
-	return &token
+	if token.WorkspaceToken == "" {
+		return nil, fmt.Errorf("%w: missing workspace_token", ErrCorruptToken)
+	}
+	return &token, nil
 }
 
-// Save writes the token to disk.
+// quarantine moves a corrupt token file aside and tells the user how to
+// recover.
+func (s *TokenStore) quarantine(cause error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	backup := s.path + ".corrupt"
+	if err := os.Rename(s.path, backup); err != nil {
+		if !os.IsNotExist(err) {
+			Warnf("%s: %v. Run `dea auth login` to re-authenticate.", s.path, cause)
+		}
+		return
+	}
+	Warnf("%s: %v (moved to %s). Run `dea auth login` to re-authenticate.", s.path, cause, backup)
+}
+
+// Save writes the token to disk atomically: a temp file in the same
+// directory is renamed over tokens.json, so a crash never leaves it truncated.
 func (s *TokenStore) Save(token *TokenData) error {
 	s.mu.Lock()
 	defer s.mu.Unlock()
 
-	if err := os.MkdirAll(config.DeaDir(), 0700); err != nil {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
 		return err
 	}
 
@@ -71,7 +144,8 @@ func (s *TokenStore) Save(token *TokenData) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(s.path, data, 0600)
+
+	return fileutil.WriteFileAtomic(s.path, data, 0600)
 }
 
 // Clear removes the stored token.
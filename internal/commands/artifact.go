@@ -1,16 +1,21 @@
 package commands
 
 import (
+	"context"
 	"crypto/sha256"
 	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"os"
 	"path/filepath"
 	"strings"
+	"sync"
+	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/fileutil"
 	"github.com/spf13/cobra"
 )
 
@@ -18,82 +23,285 @@ import (
 type StagedArtifact struct {
 	FilePath string `json:"file_path"`
 	CardID   string `json:"card_id"`
+	Status   string `json:"status,omitempty"`
+
+	// SourcePath is the original file when FilePath is a snapshot taken
+	// by stage --copy or --move.
+	SourcePath string `json:"source_path,omitempty"`
+}
+
+// artifactStatusFailed marks a staged artifact whose last push attempt failed.
+const artifactStatusFailed = "failed"
+
+// stagedArtifactsLockTimeout bounds how long a command waits for another
+// dea process to finish updating the staging list.
+const stagedArtifactsLockTimeout = 10 * time.Second
+
+// stagedArtifactsPath is the staging list in the context directory.
+func stagedArtifactsPath() string {
+	return contextPath("staged-artifacts.json")
 }
 
-const stagedArtifactsPath = ".dea-context/staged-artifacts.json"
+// artifactSnapshotDir holds the copies made by stage --copy and --move, one
+// subdirectory per card.
+func artifactSnapshotDir() string {
+	return contextPath("artifacts")
+}
+
+// allCards is the --card value that selects every card in the staging list.
+const allCards = "all"
+
+// defaultPushConcurrency is how many artifacts are uploaded in parallel.
+const defaultPushConcurrency = 4
 
 func newArtifactCommand() *cobra.Command {
 	cmd := &cobra.Command{
 		Use:   "artifact",
-		Short: "Stage or push artifacts for a card",
+		Short: "Stage, push or remove artifacts for a card",
 	}
 
 	cmd.AddCommand(newArtifactStageCommand())
 	cmd.AddCommand(newArtifactPushCommand())
+	cmd.AddCommand(newArtifactClearCommand())
+	cmd.AddCommand(newArtifactVerifyCommand())
+	cmd.AddCommand(newArtifactRmCommand())
 
 	return cmd
 }
 
 func newArtifactStageCommand() *cobra.Command {
-	var cardID string
+	var (
+		cardID     string
+		maxSize    string
+		allowLarge bool
+		copyFile   bool
+		moveFile   bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "stage <file>",
 		Short: "Stage a file for a card (does not upload yet)",
-		Args:  cobra.ExactArgs(1),
+		Long: `Stage a file for a card. It is uploaded by ` + "`dea artifact push`" + ` or ` + "`dea done`" + `.
+
+By default the staging list references the file where it is, so the push
+uploads whatever it contains by then. With --copy (or --move) the file is
+snapshotted into artifacts/<card-id>/ in the context directory (` + defaultContextDir + `
+unless context_dir is set) and that snapshot is pushed, so later edits to the
+original don't change what is uploaded.`,
+		Example: `  # Stage a file for the current card
+  dea artifact stage docs/design.md
+
+  # Snapshot a file so edits after staging aren't pushed
+  dea artifact stage report.md --copy
+
+  # Stage for a specific card, allowing a file above the size limit
+  dea artifact stage build/report.pdf --card card-123 --allow-large`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
 			filePath := args[0]
 
-			if cardID == "" {
-				data, err := os.ReadFile(".dea-context/.current-card")
-				if err != nil || len(data) == 0 {
-					return fmt.Errorf("--card is required (or run `dea claim <card-id>` first)")
-				}
-				cardID = strings.TrimSpace(string(data))
+			cardID, err := cardFlagOrCurrent(cardID)
+			if err != nil {
+				return err
 			}
 
-			if _, err := os.Stat(filePath); os.IsNotExist(err) {
+			info, err := os.Stat(filePath)
+			if os.IsNotExist(err) {
 				return fmt.Errorf("file not found: %s", filePath)
 			}
+			if err != nil {
+				return fmt.Errorf("cannot stat file: %w", err)
+			}
 
-			staged, err := loadStagedArtifacts()
+			limit, err := artifactSizeLimit(maxSize, allowLarge)
 			if err != nil {
-				staged = []StagedArtifact{}
+				return err
+			}
+			if err := checkArtifactSize(info.Size(), limit); err != nil {
+				return fmt.Errorf("%s: %w", filePath, err)
 			}
 
-			staged = append(staged, StagedArtifact{
-				FilePath: filePath,
-				CardID:   cardID,
-			})
+			// A shared context directory outlives the working directory,
+			// so relative paths would break after a cd.
+			if cfg.ContextDir != "" {
+				if abs, err := filepath.Abs(filePath); err == nil {
+					filePath = abs
+				}
+			}
+			artifact := StagedArtifact{FilePath: filePath, CardID: cardID}
+			if copyFile || moveFile {
+				snapshot, err := snapshotArtifact(filePath, cardID, moveFile)
+				if err != nil {
+					return err
+				}
+				artifact.FilePath, artifact.SourcePath = snapshot, filePath
+			}
 
-			if err := saveStagedArtifacts(staged); err != nil {
+			err = updateStagedArtifacts(func(staged []StagedArtifact) ([]StagedArtifact, error) {
+				return append(staged, artifact), nil
+			})
+			if err != nil {
 				return fmt.Errorf("failed to save staged artifacts: %w", err)
 			}
 
+			if artifact.SourcePath != "" {
+				fmt.Printf("Staged: %s (snapshot %s) -> card %s\n", filePath, artifact.FilePath, cardID)
+				return nil
+			}
 			fmt.Printf("Staged: %s -> card %s\n", filePath, cardID)
 			return nil
 		},
 	}
 
 	cmd.Flags().StringVar(&cardID, "card", "", "Card ID to stage the artifact for")
+	addArtifactSizeFlags(cmd, &maxSize, &allowLarge)
+	cmd.Flags().BoolVar(&copyFile, "copy", false, "Stage a snapshot copy of the file instead of the file itself")
+	cmd.Flags().BoolVar(&moveFile, "move", false, "Like --copy, but move the file into the snapshot area")
+	cmd.MarkFlagsMutuallyExclusive("copy", "move")
+	return cmd
+}
+
+// snapshotArtifact copies (or, with move, moves) filePath into the card's
+// snapshot directory and returns the snapshot's path. Each source path gets
+// its own subdirectory, named from a hash of its absolute path, so files
+// with the same name in different directories don't overwrite each other's
+// snapshots while the pushed filename stays the original. Restaging the
+// same file replaces its earlier snapshot.
+func snapshotArtifact(filePath, cardID string, move bool) (string, error) {
+	abs, err := filepath.Abs(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot resolve %s: %w", filePath, err)
+	}
+	sum := sha256.Sum256([]byte(abs))
+	dir := filepath.Join(artifactSnapshotDir(), cardID, hex.EncodeToString(sum[:])[:12])
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return "", fmt.Errorf("failed to create %s: %w", dir, err)
+	}
+	dst := filepath.Join(dir, filepath.Base(filePath))
+
+	if move {
+		// Rename fails across filesystems; fall back to copy and remove.
+		if err := os.Rename(filePath, dst); err == nil {
+			return dst, nil
+		}
+	}
+
+	src, err := os.Open(filePath)
+	if err != nil {
+		return "", fmt.Errorf("cannot open file: %w", err)
+	}
+	defer src.Close()
+	info, err := src.Stat()
+	if err != nil {
+		return "", fmt.Errorf("cannot stat file: %w", err)
+	}
+	if err := fileutil.WriteReaderAtomic(dst, src, info.Mode().Perm()); err != nil {
+		return "", fmt.Errorf("failed to snapshot %s: %w", filePath, err)
+	}
+
+	if move {
+		src.Close()
+		if err := os.Remove(filePath); err != nil {
+			logWarn("snapshot made but could not remove %s: %v", filePath, err)
+		}
+	}
+	return dst, nil
+}
+
+func newArtifactClearCommand() *cobra.Command {
+	var (
+		cardID string
+		all    bool
+		yes    bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "clear",
+		Short: "Remove staged artifacts without pushing them",
+		Long: `Remove a card's entries from the staging list, or every card's with --all.
+Files on disk, including --copy and --move snapshots, are left in place.`,
+		Example: `  # Start over on the current card
+  dea artifact clear
+
+  # Drop everything staged in this directory
+  dea artifact clear --all --yes`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if all && !yes {
+				return fmt.Errorf("--all clears staged artifacts for every card; pass --yes to confirm")
+			}
+			if !all {
+				var err error
+				cardID, err = cardFlagOrCurrent(cardID)
+				if err != nil {
+					return err
+				}
+			}
+
+			cleared := 0
+			err := updateStagedArtifacts(func(staged []StagedArtifact) ([]StagedArtifact, error) {
+				remaining := staged[:0]
+				for _, a := range staged {
+					if all || a.CardID == cardID {
+						cleared++
+						continue
+					}
+					remaining = append(remaining, a)
+				}
+				return remaining, nil
+			})
+			if err != nil {
+				return fmt.Errorf("failed to save staged artifacts: %w", err)
+			}
+
+			if all {
+				fmt.Printf("Cleared %d staged artifact(s).\n", cleared)
+				return nil
+			}
+			fmt.Printf("Cleared %d staged artifact(s) for card %s.\n", cleared, cardID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cardID, "card", "", "Card to clear staged artifacts for (default: current card)")
+	cmd.Flags().BoolVar(&all, "all", false, "Clear staged artifacts for every card")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Confirm clearing with --all")
+	cmd.MarkFlagsMutuallyExclusive("card", "all")
 	return cmd
 }
 
 func newArtifactPushCommand() *cobra.Command {
-	var cardID string
+	var (
+		cardID      string
+		concurrency int
+		onlyFailed  bool
+		maxSize     string
+		allowLarge  bool
+		manifest    string
+	)
 
 	cmd := &cobra.Command{
 		Use:   "push",
 		Short: "Push staged artifacts for a card to the workspace API",
+		Example: `  # Push everything staged for the current card
+  dea artifact push
+
+  # Push staged artifacts for every card
+  dea artifact push --card all
+
+  # Retry only the artifacts whose last push failed
+  dea artifact push --card card-123 --only-failed
+
+  # Record exactly what was pushed, e.g. to commit alongside the change
+  dea artifact push --manifest artifacts.json`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := mustLoadToken()
 
-			if cardID == "" {
-				data, err := os.ReadFile(".dea-context/.current-card")
-				if err != nil || len(data) == 0 {
-					return fmt.Errorf("--card is required (or run `dea claim <card-id>` first)")
+			if cardID != allCards {
+				var err error
+				if cardID, err = cardFlagOrCurrent(cardID); err != nil {
+					return err
 				}
-				cardID = strings.TrimSpace(string(data))
 			}
 
 			staged, err := loadStagedArtifacts()
@@ -102,62 +310,217 @@ func newArtifactPushCommand() *cobra.Command {
 				return nil
 			}
 
-			// Partition: items for this card vs. items for other cards.
+			all := cardID == allCards
 			var toPush []StagedArtifact
-			var remaining []StagedArtifact
 			for _, a := range staged {
-				if a.CardID == cardID {
+				if (all || a.CardID == cardID) && (!onlyFailed || a.Status == artifactStatusFailed) {
 					toPush = append(toPush, a)
-				} else {
-					remaining = append(remaining, a)
 				}
 			}
 
 			if len(toPush) == 0 {
-				fmt.Printf("No staged artifacts for card %s.\n", cardID)
+				what := "staged"
+				if onlyFailed {
+					what = "failed"
+				}
+				if all {
+					fmt.Printf("No %s artifacts to push.\n", what)
+				} else {
+					fmt.Printf("No %s artifacts for card %s.\n", what, cardID)
+				}
 				return nil
 			}
 
-			pushedCount := 0
-			for _, artifact := range toPush {
-				if err := pushArtifact(artifact.FilePath, cardID, token.WorkspaceID); err != nil {
-					return fmt.Errorf("failed to push %s: %w", artifact.FilePath, err)
+			if concurrency < 1 {
+				return fmt.Errorf("--concurrency must be at least 1")
+			}
+			limit, err := artifactSizeLimit(maxSize, allowLarge)
+			if err != nil {
+				return err
+			}
+
+			results, errs := pushArtifacts(cmd.Context(), toPush, token.WorkspaceID, concurrency, limit, os.Stdout)
+
+			if manifest != "" {
+				if err := writePushManifest(manifest, results, errs); err != nil {
+					logWarn("failed to write manifest: %v", err)
 				}
-				pushedCount++
 			}
 
-			// Update staging list — keep items for other cards.
-			if err := saveStagedArtifacts(remaining); err != nil {
-				fmt.Fprintf(os.Stderr, "warning: failed to update staged artifacts: %v\n", err)
+			// Drop what was pushed and mark what failed, so a re-run retries
+			// exactly what did not make it.
+			var cards []string
+			pushed := make(map[string]int)
+			failed := make(map[string]int)
+			var failures []string
+			skipped := 0
+			for i, artifact := range toPush {
+				if _, ok := pushed[artifact.CardID]; !ok {
+					cards = append(cards, artifact.CardID)
+					pushed[artifact.CardID] = 0
+				}
+				if errors.Is(errs[i], context.Canceled) {
+					skipped++
+					continue
+				}
+				if errs[i] != nil {
+					failed[artifact.CardID]++
+					failures = append(failures, fmt.Sprintf("  %s: %v", artifact.FilePath, errs[i]))
+					continue
+				}
+				pushed[artifact.CardID]++
+			}
+			if err := settleStagedArtifacts(toPush, errs); err != nil {
+				logWarn("failed to update staged artifacts: %v", err)
 			}
 
-			fmt.Printf("Pushed %d artifact(s) for card %s.\n", pushedCount, cardID)
+			for _, id := range cards {
+				if failed[id] > 0 {
+					fmt.Printf("Pushed %d artifact(s) for card %s (%d failed).\n", pushed[id], id, failed[id])
+				} else {
+					fmt.Printf("Pushed %d artifact(s) for card %s.\n", pushed[id], id)
+				}
+			}
+			if len(failures) > 0 {
+				logError("Failed to push %d artifact(s):\n%s", len(failures), strings.Join(failures, "\n"))
+			}
+			if skipped > 0 {
+				return fmt.Errorf("interrupted; %d artifact(s) left staged", skipped)
+			}
+			if len(failures) > 0 {
+				return fmt.Errorf("failed to push %d of %d artifact(s)", len(failures), len(toPush))
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&cardID, "card", "", "Card ID to push artifacts for")
+	cmd.Flags().StringVar(&cardID, "card", "", `Card ID to push artifacts for, or "all" for every staged card`)
+	cmd.Flags().IntVar(&concurrency, "concurrency", defaultPushConcurrency, "Number of artifacts to push in parallel")
+	cmd.Flags().BoolVar(&onlyFailed, "only-failed", false, "Retry only artifacts whose previous push failed")
+	addArtifactSizeFlags(cmd, &maxSize, &allowLarge)
+	cmd.Flags().StringVar(&manifest, "manifest", "", "Write a JSON manifest of the artifacts pushed to this file")
 	return cmd
 }
 
-func pushArtifact(filePath, cardID, workspaceID string) error {
+// pushedArtifact describes one artifact registration sent by pushArtifact.
+type pushedArtifact struct {
+	CardID     string    `json:"card_id"`
+	Filename   string    `json:"filename"`
+	Path       string    `json:"path"`
+	FileHash   string    `json:"file_hash"`
+	FileSize   int64     `json:"file_size"`
+	FileType   string    `json:"file_type"`
+	ArtifactID string    `json:"artifact_id,omitempty"`
+	PushedAt   time.Time `json:"pushed_at"`
+
+	// Queued is set when the registration was queued offline rather than
+	// sent, so there is no server ID yet.
+	Queued bool `json:"-"`
+}
+
+// pushManifest is the file written by `artifact push --manifest`.
+type pushManifest struct {
+	GeneratedAt time.Time        `json:"generated_at"`
+	Artifacts   []pushedArtifact `json:"artifacts"`
+}
+
+// writePushManifest writes the artifacts that reached the server to path.
+// Failed pushes and ones only queued offline are left out.
+func writePushManifest(path string, results []pushedArtifact, errs []error) error {
+	m := pushManifest{GeneratedAt: time.Now().UTC(), Artifacts: []pushedArtifact{}}
+	queued := 0
+	for i, r := range results {
+		switch {
+		case errs[i] != nil:
+		case r.Queued:
+			queued++
+		default:
+			m.Artifacts = append(m.Artifacts, r)
+		}
+	}
+	if queued > 0 {
+		logWarn("%d artifact(s) queued offline are not in the manifest", queued)
+	}
+
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := fileutil.WriteFileAtomic(path, append(data, '\n'), 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Wrote manifest of %d artifact(s) to %s.\n", len(m.Artifacts), path)
+	return nil
+}
+
+// pushArtifacts pushes artifacts using a pool of up to concurrency workers.
+// Files larger than maxSize fail without being uploaded; zero means no limit.
+// Once ctx is cancelled no new uploads start and the remaining artifacts get
+// ctx's error. The returned slice holds the outcome for each artifact in
+// input order, alongside what was sent for each.
+func pushArtifacts(ctx context.Context, artifacts []StagedArtifact, workspaceID string, concurrency int, maxSize int64, progress io.Writer) ([]pushedArtifact, []error) {
+	results := make([]pushedArtifact, len(artifacts))
+	errs := make([]error, len(artifacts))
+	if concurrency > len(artifacts) {
+		concurrency = len(artifacts)
+	}
+
+	jobs := make(chan int)
+	var wg sync.WaitGroup
+	for w := 0; w < concurrency; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				if err := ctx.Err(); err != nil {
+					errs[i] = err
+					continue
+				}
+				a := artifacts[i]
+				results[i], errs[i] = pushArtifact(a.FilePath, a.CardID, workspaceID, maxSize, progress)
+			}
+		}()
+	}
+
+	for i := range artifacts {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, errs
+}
+
+// pushArtifact registers the file at filePath as an artifact of cardID,
+// writing a line to progress once it is pushed or queued offline.
+func pushArtifact(filePath, cardID, workspaceID string, maxSize int64, progress io.Writer) (pushedArtifact, error) {
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return pushedArtifact{}, fmt.Errorf("cannot stat file: %w", err)
+	}
+	if err := checkArtifactSize(info.Size(), maxSize); err != nil {
+		return pushedArtifact{}, err
+	}
+
 	f, err := os.Open(filePath)
 	if err != nil {
-		return fmt.Errorf("cannot open file: %w", err)
+		return pushedArtifact{}, fmt.Errorf("cannot open file: %w", err)
 	}
 	defer f.Close()
 
 	fileData, err := io.ReadAll(f)
 	if err != nil {
-		return fmt.Errorf("cannot read file: %w", err)
+		return pushedArtifact{}, fmt.Errorf("cannot read file: %w", err)
 	}
 
 	h := sha256.Sum256(fileData)
 	fileHash := hex.EncodeToString(h[:])
 
-	info, err := os.Stat(filePath)
+	// Record where the file is independently of the working directory, so
+	// a queued registration can be checked against it at flush time.
+	storagePath, err := filepath.Abs(filePath)
 	if err != nil {
-		return fmt.Errorf("cannot stat file: %w", err)
+		storagePath = filePath
 	}
 
 	filename := filepath.Base(filePath)
@@ -169,22 +532,71 @@ func pushArtifact(filePath, cardID, workspaceID string) error {
 		"filename":     filename,
 		"file_type":    fileType,
 		"file_hash":    fileHash,
-		"storage_path": filePath, // local path for Phase 1a; GCS in Phase 1b
+		"storage_path": storagePath, // local path for Phase 1a; GCS in Phase 1b
 		"file_size":    info.Size(),
 	}
+	result := pushedArtifact{
+		CardID:   cardID,
+		Filename: filename,
+		Path:     filePath,
+		FileHash: fileHash,
+		FileSize: info.Size(),
+		FileType: fileType,
+		PushedAt: time.Now().UTC(),
+	}
 
-	_, err = apiClient.Post(api.PathArtifacts, body)
+	data, queued, err := apiPost(api.PathArtifacts, body)
 	if err != nil {
-		if isNetworkErr(err) {
-			if qErr := offQueue.Add("POST", api.PathArtifacts, body); qErr == nil {
-				fmt.Println("Queued offline. Will flush on next connection.")
-			}
-			return err
-		}
-		return err
+		return result, err
+	}
+	if queued {
+		result.Queued = true
+		fmt.Fprintf(progress, "  Queued offline: %s. Will flush on next connection.\n", filename)
+		return result, nil
+	}
+	if resp, err := unwrapObject(data, "artifact"); err == nil {
+		result.ArtifactID = strField(resp, "id", strField(resp, "artifact_id", ""))
+	}
+
+	fmt.Fprintf(progress, "  Pushed: %s (%s, %d bytes)\n", filename, fileType, info.Size())
+	return result, nil
+}
+
+// addArtifactSizeFlags registers --max-size and --allow-large.
+func addArtifactSizeFlags(cmd *cobra.Command, maxSize *string, allowLarge *bool) {
+	cmd.Flags().StringVar(maxSize, "max-size", "",
+		"Reject files larger than this, e.g. 100MB (default: artifact_max_size in config)")
+	cmd.Flags().BoolVar(allowLarge, "allow-large", false, "Ignore the artifact size limit")
+}
+
+// artifactSizeLimit resolves the artifact size limit in bytes from --max-size,
+// then artifact_max_size in config. Zero means no limit.
+func artifactSizeLimit(flagValue string, allowLarge bool) (int64, error) {
+	if allowLarge {
+		return 0, nil
 	}
 
-	fmt.Printf("  Pushed: %s (%s, %d bytes)\n", filename, fileType, info.Size())
+	value, source := flagValue, "--max-size"
+	if value == "" {
+		value, source = cfg.ArtifactMaxSize, "artifact_max_size"
+	}
+	if value == "" {
+		return 0, nil
+	}
+
+	limit, err := parseSize(value)
+	if err != nil {
+		return 0, fmt.Errorf("%s: %w", source, err)
+	}
+	return limit, nil
+}
+
+// checkArtifactSize rejects a file of size bytes when it exceeds limit.
+func checkArtifactSize(size, limit int64) error {
+	if limit > 0 && size > limit {
+		return fmt.Errorf("file is %s, over the %s artifact size limit (use --allow-large to override)",
+			formatSize(size), formatSize(limit))
+	}
 	return nil
 }
 
@@ -226,7 +638,7 @@ func inferFileType(filename string) string {
 }
 
 func loadStagedArtifacts() ([]StagedArtifact, error) {
-	data, err := os.ReadFile(stagedArtifactsPath)
+	data, err := os.ReadFile(stagedArtifactsPath())
 	if os.IsNotExist(err) {
 		return []StagedArtifact{}, nil
 	}
@@ -241,13 +653,67 @@ func loadStagedArtifacts() ([]StagedArtifact, error) {
 	return items, nil
 }
 
+// updateStagedArtifacts applies fn to the staging list under a lock file, so
+// scripts that stage and push in parallel cannot drop each other's entries.
+func updateStagedArtifacts(fn func([]StagedArtifact) ([]StagedArtifact, error)) error {
+	if err := ensureContextDir(); err != nil {
+		return err
+	}
+	unlock, err := fileutil.Lock(stagedArtifactsPath()+".lock", stagedArtifactsLockTimeout)
+	if err != nil {
+		return err
+	}
+	defer unlock()
+
+	staged, err := loadStagedArtifacts()
+	if err != nil {
+		return err
+	}
+	staged, err = fn(staged)
+	if err != nil {
+		return err
+	}
+	return saveStagedArtifacts(staged)
+}
+
+// settleStagedArtifacts records the outcome of pushing pushed (errs is indexed
+// alongside it): successes leave the staging list and failures are marked.
+// Artifacts skipped because the push was cancelled are left as they were.
+// The list is re-read under the lock, so entries staged while the push was
+// running are kept.
+func settleStagedArtifacts(pushed []StagedArtifact, errs []error) error {
+	type key struct{ file, card string }
+	failed := make(map[key]bool, len(pushed))
+	for i, a := range pushed {
+		if errors.Is(errs[i], context.Canceled) {
+			continue
+		}
+		failed[key{a.FilePath, a.CardID}] = errs[i] != nil
+	}
+
+	return updateStagedArtifacts(func(staged []StagedArtifact) ([]StagedArtifact, error) {
+		remaining := staged[:0]
+		for _, a := range staged {
+			isFailed, ok := failed[key{a.FilePath, a.CardID}]
+			switch {
+			case !ok:
+				remaining = append(remaining, a)
+			case isFailed:
+				a.Status = artifactStatusFailed
+				remaining = append(remaining, a)
+			}
+		}
+		return remaining, nil
+	})
+}
+
 func saveStagedArtifacts(items []StagedArtifact) error {
-	if err := os.MkdirAll(".dea-context", 0755); err != nil {
+	if err := ensureContextDir(); err != nil {
 		return err
 	}
 	data, err := json.MarshalIndent(items, "", "  ")
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(stagedArtifactsPath, data, 0644)
+	return fileutil.WriteFileAtomic(stagedArtifactsPath(), data, 0644)
 }
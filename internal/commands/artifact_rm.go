@@ -0,0 +1,115 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"path/filepath"
+	"strings"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newArtifactRmCommand() *cobra.Command {
+	var (
+		cardID     string
+		artifactID string
+		yes        bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "rm [filename]",
+		Short: "Delete an artifact registered on a card",
+		Long: `Delete a pushed artifact, identified by its filename on a card or by
+its artifact ID. Asks for confirmation unless --yes is given. When offline
+the delete is queued like other writes.`,
+		Example: `  # Remove a file pushed to the current card by mistake
+  dea artifact rm notes-draft.md
+
+  # Remove by artifact ID without prompting
+  dea artifact rm --id art-42 --yes`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if (len(args) == 0) == (artifactID == "") {
+				return fmt.Errorf("give exactly one of a filename or --id")
+			}
+			mustLoadToken()
+
+			label := artifactID
+			if len(args) > 0 {
+				var err error
+				cardID, err = cardFlagOrCurrent(cardID)
+				if err != nil {
+					return err
+				}
+				artifactID, err = findArtifactID(cardID, args[0])
+				if err != nil {
+					return err
+				}
+				label = fmt.Sprintf("%s (%s) from card %s", args[0], artifactID, cardID)
+			}
+
+			if !yes {
+				ok, err := confirm(fmt.Sprintf("Delete artifact %s?", label))
+				if err != nil {
+					return err
+				}
+				if !ok {
+					fmt.Println("Aborted.")
+					return nil
+				}
+			}
+
+			queued, err := apiDelete(api.ArtifactPath(artifactID))
+			if err != nil {
+				return handleAPIError(err, "artifact", artifactID, "delete")
+			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
+			fmt.Printf("Deleted artifact %s.\n", artifactID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cardID, "card", "", "Card the artifact belongs to (default: current card)")
+	cmd.Flags().StringVar(&artifactID, "id", "", "Artifact ID to delete instead of a filename")
+	cmd.Flags().BoolVarP(&yes, "yes", "y", false, "Delete without asking for confirmation")
+	cmd.MarkFlagsMutuallyExclusive("id", "card")
+	return cmd
+}
+
+// findArtifactID looks up the ID of the artifact registered on cardID under
+// filename, matching either its recorded filename or the base name of its
+// storage path.
+func findArtifactID(cardID, filename string) (string, error) {
+	data, err := apiClient.Get(api.PathArtifacts + "?card_id=" + url.QueryEscape(cardID))
+	if err != nil {
+		return "", handleAPIError(err, "artifacts for card", cardID, "list")
+	}
+	artifacts, err := unwrapList(data, "artifacts")
+	if err != nil {
+		return "", fmt.Errorf("unexpected artifacts response: %w", err)
+	}
+
+	var ids []string
+	for _, a := range artifacts {
+		name := strField(a, "filename", strField(a, "file_name", ""))
+		if name != filename && filepath.Base(strField(a, "storage_path", "")) != filename {
+			continue
+		}
+		ids = append(ids, strField(a, "id", strField(a, "artifact_id", "")))
+	}
+
+	switch {
+	case len(ids) == 0:
+		return "", fmt.Errorf("no artifact named %q on card %s", filename, cardID)
+	case len(ids) > 1:
+		return "", fmt.Errorf("%d artifacts named %q on card %s (%s); pass --id to pick one",
+			len(ids), filename, cardID, strings.Join(ids, ", "))
+	case ids[0] == "":
+		return "", fmt.Errorf("artifact %q on card %s has no ID", filename, cardID)
+	}
+	return ids[0], nil
+}
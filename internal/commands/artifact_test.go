@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSnapshotArtifactKeepsSameNamedFilesApart(t *testing.T) {
+	root := t.TempDir()
+	old := contextDir
+	contextDir = filepath.Join(root, ".dea-context")
+	defer func() { contextDir = old }()
+
+	var snapshots []string
+	for _, dir := range []string{"a", "b"} {
+		src := filepath.Join(root, dir, "report.md")
+		if err := os.MkdirAll(filepath.Dir(src), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(src, []byte(dir), 0644); err != nil {
+			t.Fatal(err)
+		}
+		snap, err := snapshotArtifact(src, "c1", false)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if filepath.Base(snap) != "report.md" {
+			t.Errorf("snapshot %s lost the original filename", snap)
+		}
+		snapshots = append(snapshots, snap)
+	}
+
+	if snapshots[0] == snapshots[1] {
+		t.Fatalf("both files snapshotted to %s", snapshots[0])
+	}
+	for i, want := range []string{"a", "b"} {
+		data, err := os.ReadFile(snapshots[i])
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != want {
+			t.Errorf("snapshot %s = %q, want %q", snapshots[i], data, want)
+		}
+	}
+
+	// Restaging the same file reuses its snapshot.
+	again, err := snapshotArtifact(filepath.Join(root, "a", "report.md"), "c1", false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again != snapshots[0] {
+		t.Errorf("restaged snapshot = %s, want %s", again, snapshots[0])
+	}
+}
@@ -0,0 +1,101 @@
+package commands
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newArtifactVerifyCommand() *cobra.Command {
+	var cardID string
+
+	cmd := &cobra.Command{
+		Use:   "verify",
+		Short: "Check local files against the hashes of a card's pushed artifacts",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mustLoadToken()
+
+			cardID, err := cardFlagOrCurrent(cardID)
+			if err != nil {
+				return err
+			}
+
+			data, err := apiClient.Get(api.PathArtifacts + "?card_id=" + url.QueryEscape(cardID))
+			if err != nil {
+				return handleAPIError(err, "artifacts for card", cardID, "list")
+			}
+			artifacts, err := unwrapList(data, "artifacts")
+			if err != nil {
+				return fmt.Errorf("unexpected artifacts response: %w", err)
+			}
+			if len(artifacts) == 0 {
+				fmt.Printf("No artifacts registered for card %s.\n", cardID)
+				return nil
+			}
+
+			var matched, mismatched, missing int
+			for _, a := range artifacts {
+				path := artifactLocalPath(a)
+				want := strings.ToLower(strField(a, "file_hash", ""))
+
+				got, err := fileSHA256(path)
+				switch {
+				case os.IsNotExist(err):
+					fmt.Printf("MISSING   %s\n", path)
+					missing++
+				case err != nil:
+					fmt.Printf("MISSING   %s (%v)\n", path, err)
+					missing++
+				case got != want:
+					fmt.Printf("MISMATCH  %s\n", path)
+					mismatched++
+				default:
+					fmt.Printf("OK        %s\n", path)
+					matched++
+				}
+			}
+
+			fmt.Printf("\n%d match, %d mismatch, %d missing.\n", matched, mismatched, missing)
+			if mismatched+missing > 0 {
+				return fmt.Errorf("%d artifact(s) for card %s do not match local files", mismatched+missing, cardID)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&cardID, "card", "", "Card ID to verify artifacts for (default: current card)")
+	return cmd
+}
+
+// artifactLocalPath is where a registered artifact should be on disk: the
+// storage_path recorded at push time, or its filename in the current
+// directory.
+func artifactLocalPath(a map[string]interface{}) string {
+	if p := strField(a, "storage_path", ""); p != "" {
+		return p
+	}
+	return strField(a, "filename", strField(a, "file_name", ""))
+}
+
+// fileSHA256 returns the hex SHA-256 of the file at path, as sent in
+// file_hash by pushArtifact.
+func fileSHA256(path string) (string, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	if _, err := io.Copy(h, f); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(h.Sum(nil)), nil
+}
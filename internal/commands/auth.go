@@ -2,15 +2,21 @@ package commands
 
 import (
 	"bufio"
+	"context"
 	"encoding/base64"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"io"
 	"os"
+	"os/signal"
 	"strings"
+	"syscall"
 	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
 	"github.com/dea-exmachina/dea-cli/internal/auth"
+	"github.com/dea-exmachina/dea-cli/internal/poll"
 	"github.com/spf13/cobra"
 )
 
@@ -22,16 +28,46 @@ func newAuthCommand() *cobra.Command {
 
 	cmd.AddCommand(newAuthLoginCommand())
 	cmd.AddCommand(newAuthStatusCommand())
+	cmd.AddCommand(newAuthWhoamiCommand())
 	cmd.AddCommand(newAuthRefreshCommand())
+	cmd.AddCommand(newAuthRevokeCommand())
+	cmd.AddCommand(newAuthImportCommand())
 	cmd.AddCommand(newAuthRotateSSHCommand())
 
 	return cmd
 }
 
+const (
+	// loginRetries is how many extra attempts login makes after a network error.
+	loginRetries = 2
+
+	// loginRetryDelay is the wait before the first retry; it doubles after each.
+	loginRetryDelay = time.Second
+
+	// devicePollInterval and deviceCodeLifetime apply when the server
+	// doesn't say how often to poll or how long a device code lasts.
+	devicePollInterval = 5 * time.Second
+	deviceCodeLifetime = 15 * time.Minute
+
+	// deviceSlowDownStep is added to the poll interval each time the server
+	// says to slow down.
+	deviceSlowDownStep = 5 * time.Second
+)
+
 func newAuthLoginCommand() *cobra.Command {
-	return &cobra.Command{
+	var (
+		noRetry bool
+		sso     bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "login",
 		Short: "Authenticate with the dea workspace and store a JWT",
+		Example: `  # Log in with an agent ID and secret key
+  dea auth login
+
+  # Log in as a person through your organization's SSO in a browser
+  dea auth login --sso`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			scanner := bufio.NewScanner(os.Stdin)
 
@@ -43,24 +79,17 @@ func newAuthLoginCommand() *cobra.Command {
 				endpoint = input
 			}
 			cfg.Endpoint = endpoint
-			apiClient = api.NewClient(endpoint, cfg.TimeoutSeconds, tokenStore)
-
-			fmt.Print("Agent ID: ")
-			scanner.Scan()
-			agentID := strings.TrimSpace(scanner.Text())
-
-			fmt.Print("Secret key: ")
-			scanner.Scan()
-			secretKey := strings.TrimSpace(scanner.Text())
-
-			if agentID == "" || secretKey == "" {
-				return fmt.Errorf("agent ID and secret key are required")
+			apiClient = newAPIClient(endpoint)
+
+			var (
+				tokenResp *api.TokenResponse
+				err       error
+			)
+			if sso {
+				tokenResp, err = deviceLogin(cmd.Context())
+			} else {
+				tokenResp, err = credentialLogin(cmd.Context(), scanner, noRetry)
 			}
-
-			tokenResp, err := apiClient.IssueToken(map[string]string{
-				"agent_id":   agentID,
-				"secret_key": secretKey,
-			})
 			if err != nil {
 				return fmt.Errorf("login failed: %w", err)
 			}
@@ -82,48 +111,210 @@ func newAuthLoginCommand() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&noRetry, "no-retry", false, "Fail on the first network error instead of retrying")
+	cmd.Flags().BoolVar(&sso, "sso", false, "Log in through SSO in a browser instead of with an agent ID and secret key")
+	return cmd
+}
+
+// credentialLogin prompts for an agent ID and secret key and exchanges them
+// for a token.
+func credentialLogin(ctx context.Context, scanner *bufio.Scanner, noRetry bool) (*api.TokenResponse, error) {
+	fmt.Print("Agent ID: ")
+	scanner.Scan()
+	agentID := strings.TrimSpace(scanner.Text())
+
+	fmt.Print("Secret key: ")
+	scanner.Scan()
+	secretKey := strings.TrimSpace(scanner.Text())
+
+	if agentID == "" || secretKey == "" {
+		return nil, fmt.Errorf("agent ID and secret key are required")
+	}
+
+	credentials := map[string]string{
+		"agent_id":   agentID,
+		"secret_key": secretKey,
+	}
+	retries := loginRetries
+	if noRetry {
+		retries = 0
+	}
+	return issueTokenWithRetry(ctx, credentials, retries)
+}
+
+// deviceLogin runs the SSO device flow: it shows the user where to approve
+// the login, then polls until they do, the code expires, or ctx is done.
+func deviceLogin(ctx context.Context) (*api.TokenResponse, error) {
+	code, err := apiClient.RequestDeviceCode()
+	if err != nil {
+		return nil, err
+	}
+
+	fmt.Printf("\nOpen %s and enter the code: %s\n", code.VerificationURI, code.UserCode)
+	if code.VerificationURIComplete != "" {
+		fmt.Printf("Or open %s to skip entering it.\n", code.VerificationURIComplete)
+	}
+	fmt.Println("Waiting for approval (Ctrl-C to cancel)...")
+
+	interval := time.Duration(code.Interval) * time.Second
+	if interval <= 0 {
+		interval = devicePollInterval
+	}
+	lifetime := time.Duration(code.ExpiresIn) * time.Second
+	if lifetime <= 0 {
+		lifetime = deviceCodeLifetime
+	}
+	ctx, cancel := context.WithTimeout(ctx, lifetime)
+	defer cancel()
+
+	var (
+		tokenResp *api.TokenResponse
+		extra     time.Duration
+	)
+	err = poll.Until(ctx, poll.Options{Interval: interval}, func(ctx context.Context) (bool, error) {
+		resp, err := apiClient.PollDeviceToken(code.DeviceCode)
+		switch {
+		case err == nil:
+			tokenResp = resp
+			return true, nil
+		case errors.Is(err, api.ErrAuthorizationPending):
+		case errors.Is(err, api.ErrSlowDown):
+			extra += deviceSlowDownStep
+		case errors.Is(err, api.ErrNetwork):
+			logWarn("%v", err)
+		default:
+			return false, err
+		}
+
+		// poll.Until keeps its interval fixed, so wait out any slow-down
+		// here.
+		if extra > 0 {
+			t := time.NewTimer(extra)
+			defer t.Stop()
+			select {
+			case <-ctx.Done():
+				return false, ctx.Err()
+			case <-t.C:
+			}
+		}
+		return false, nil
+	})
+	if errors.Is(err, context.DeadlineExceeded) {
+		return nil, api.ErrDeviceCodeExpired
+	}
+	if err != nil {
+		return nil, err
+	}
+	return tokenResp, nil
+}
+
+// issueTokenWithRetry calls IssueToken, retrying up to retries times with
+// exponential backoff when the request fails at the network level. Rejected
+// credentials are not retried.
+func issueTokenWithRetry(ctx context.Context, credentials map[string]string, retries int) (*api.TokenResponse, error) {
+	delay := loginRetryDelay
+	for attempt := 0; ; attempt++ {
+		resp, err := apiClient.IssueToken(credentials)
+		if err == nil || !errors.Is(err, api.ErrNetwork) || attempt >= retries {
+			return resp, err
+		}
+		logWarn("%v; retrying in %s...", err, delay)
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return nil, fmt.Errorf("request aborted: %w", ctx.Err())
+		case <-t.C:
+		}
+		delay *= 2
+	}
+}
+
+// authStatus is the structured form of `dea auth status`.
+type authStatus struct {
+	Authenticated bool       `json:"authenticated"`
+	AgentID       string     `json:"agent_id,omitempty"`
+	WorkspaceID   string     `json:"workspace_id,omitempty"`
+	Scopes        []string   `json:"scopes,omitempty"`
+	ExpiresAt     *time.Time `json:"expires_at,omitempty"`
+
+	// Server is the --check-server verdict: "valid", "expired" or
+	// "revoked".
+	Server string `json:"server,omitempty"`
+}
+
+// Server verdicts reported by `auth status --check-server`.
+const (
+	serverTokenValid   = "valid"
+	serverTokenExpired = "expired"
+	serverTokenRevoked = "revoked"
+)
+
+// authStatusOf describes token, which may be nil.
+func authStatusOf(token *auth.TokenData) authStatus {
+	if token == nil {
+		return authStatus{}
+	}
+	agentID, workspaceID, claims := tokenIdentity(token)
+	return authStatus{
+		Authenticated: true,
+		AgentID:       agentID,
+		WorkspaceID:   workspaceID,
+		Scopes:        claimScopes(claims),
+		ExpiresAt:     &token.ExpiresAt,
+	}
 }
 
 func newAuthStatusCommand() *cobra.Command {
-	return &cobra.Command{
+	var (
+		checkServer bool
+		out         outputOptions
+	)
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show current authentication status",
+		Long: `Show the stored token's agent, workspace, scopes and expiry, decoded locally.
+
+With --check-server, also ask the API whether it still accepts the token,
+which catches tokens revoked before they expire. The command then exits
+non-zero if the token is expired or revoked.`,
+		Example: `  # Confirm the server still honours the token before a long job
+  dea auth status --check-server`,
 		RunE: func(cmd *cobra.Command, args []string) error {
 			token := tokenStore.Load()
 			if token == nil {
+				if ok, err := out.render(authStatus{}); ok || err != nil {
+					return err
+				}
 				fmt.Println("Not authenticated. Run `dea auth login`.")
 				return nil
 			}
 
-			// Decode JWT claims (no verification — just read).
-			claims, err := decodeJWTClaims(token.WorkspaceToken)
-			if err != nil {
-				claims = map[string]interface{}{}
+			status := authStatusOf(token)
+			if checkServer {
+				verdict, err := checkTokenWithServer(token)
+				if err != nil {
+					return err
+				}
+				status.Server = verdict
 			}
-
-			agentID := token.AgentID
-			if v, ok := claims["agent_id"].(string); ok && v != "" {
-				agentID = v
+			// A token the server rejects fails the command, after the
+			// status has been shown.
+			var result error
+			if status.Server == serverTokenExpired || status.Server == serverTokenRevoked {
+				result = &exitError{code: 1}
 			}
-
-			workspaceID := token.WorkspaceID
-			if v, ok := claims["workspace_id"].(string); ok && v != "" {
-				workspaceID = v
-			}
-
-			scopes := ""
-			if v, ok := claims["scopes"]; ok {
-				switch s := v.(type) {
-				case []interface{}:
-					parts := make([]string, 0, len(s))
-					for _, scope := range s {
-						parts = append(parts, fmt.Sprintf("%v", scope))
-					}
-					scopes = strings.Join(parts, ", ")
-				case string:
-					scopes = s
+			if ok, err := out.render(status); ok || err != nil {
+				if err != nil {
+					return err
 				}
+				return result
 			}
+			agentID, workspaceID := status.AgentID, status.WorkspaceID
+
+			scopes := strings.Join(status.Scopes, ", ")
 
 			now := time.Now()
 			timeUntil := token.ExpiresAt.Sub(now)
@@ -139,22 +330,152 @@ func newAuthStatusCommand() *cobra.Command {
 			fmt.Printf("  Expires:    %s (in %dh %dm)\n",
 				token.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"),
 				hoursLeft, minutesLeft)
+			switch status.Server {
+			case serverTokenValid:
+				fmt.Println("  Server:     token accepted")
+			case serverTokenExpired:
+				fmt.Println("  Server:     token expired. Run `dea auth refresh` or `dea auth login`")
+			case serverTokenRevoked:
+				fmt.Println("  Server:     token rejected (revoked or invalid). Run `dea auth login`")
+			}
+
+			return result
+		},
+	}
+
+	addOutputFlags(cmd, &out)
+	cmd.Flags().BoolVar(&checkServer, "check-server", false, "Also check that the API still accepts the token")
+	return cmd
+}
+
+// checkTokenWithServer asks the API whether it accepts token and returns
+// serverTokenValid, serverTokenExpired or serverTokenRevoked. A token past
+// its exp is reported expired without a request.
+func checkTokenWithServer(token *auth.TokenData) (string, error) {
+	if tokenStore.Expired(token) {
+		return serverTokenExpired, nil
+	}
+	err := apiClient.VerifyToken(api.PathCards + "?limit=1")
+	switch {
+	case err == nil:
+		return serverTokenValid, nil
+	case errors.Is(err, api.ErrUnauthorized):
+		return serverTokenRevoked, nil
+	}
+	return "", fmt.Errorf("could not check token with the server: %w", err)
+}
+
+func newAuthWhoamiCommand() *cobra.Command {
+	var requireScopes []string
+
+	cmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Print the authenticated agent, optionally checking token scopes",
+		Long: `Print the authenticated agent and workspace.
+
+With --require-scope, exit non-zero unless the token holds every listed
+scope, naming the ones missing. A scope ending in ":*" (or "*" alone) held by
+the token covers the scopes under it.`,
+		Example: `  # Fail a CI job early if the token cannot push artifacts
+  dea auth whoami --require-scope artifacts:write`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := tokenStore.Load()
+			if token == nil {
+				return fmt.Errorf("not authenticated. Run `dea auth login`")
+			}
+			if tokenStore.Expired(token) {
+				return fmt.Errorf("token expired at %s. Run `dea auth refresh`",
+					token.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+			}
+
+			agentID, workspaceID, claims := tokenIdentity(token)
+			fmt.Printf("%s (workspace %s)\n", agentID, workspaceID)
 
+			held := claimScopes(claims)
+			var missing []string
+			for _, want := range requireScopes {
+				if want = strings.TrimSpace(want); want != "" && !hasScope(held, want) {
+					missing = append(missing, want)
+				}
+			}
+			if len(missing) > 0 {
+				return fmt.Errorf("token is missing required scope(s): %s", strings.Join(missing, ", "))
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().StringSliceVar(&requireScopes, "require-scope", nil,
+		"Exit non-zero unless the token has this scope (repeatable or comma-separated)")
+	return cmd
+}
+
+// tokenIdentity returns the agent and workspace a token acts as, preferring
+// its JWT claims (decoded without verification) over the stored fields.
+func tokenIdentity(token *auth.TokenData) (agentID, workspaceID string, claims map[string]interface{}) {
+	claims, err := decodeJWTClaims(token.WorkspaceToken)
+	if err != nil {
+		claims = map[string]interface{}{}
+	}
+
+	agentID = token.AgentID
+	if v, ok := claims["agent_id"].(string); ok && v != "" {
+		agentID = v
+	}
+	workspaceID = token.WorkspaceID
+	if v, ok := claims["workspace_id"].(string); ok && v != "" {
+		workspaceID = v
+	}
+	return agentID, workspaceID, claims
+}
+
+// hasScope reports whether held grants want, directly or through a wildcard
+// such as "artifacts:*" or "*".
+func hasScope(held []string, want string) bool {
+	for _, s := range held {
+		if s == want || s == "*" {
+			return true
+		}
+		if prefix, ok := strings.CutSuffix(s, "*"); ok && strings.HasSuffix(prefix, ":") && strings.HasPrefix(want, prefix) {
+			return true
+		}
+	}
+	return false
 }
 
 func newAuthRefreshCommand() *cobra.Command {
-	return &cobra.Command{
+	var (
+		force  bool
+		daemon bool
+	)
+
+	cmd := &cobra.Command{
 		Use:   "refresh",
 		Short: "Manually refresh the workspace token",
+		Long: `Refresh the workspace token. This is a no-op while the token is outside the
+refresh window (4h before expiry) unless --force is passed.
+
+With --daemon, keep running in the foreground and refresh the token each time
+it enters the refresh window, for long-lived containers where a supervisor
+manages the process. A token replaced by another process is picked up. The
+daemon exits cleanly on SIGTERM or Ctrl-C.`,
 		RunE: func(cmd *cobra.Command, args []string) error {
+			if daemon {
+				return runRefreshDaemon(cmd.Context())
+			}
+
 			token := tokenStore.Load()
 			if token == nil {
 				return fmt.Errorf("not authenticated. Run `dea auth login`")
 			}
 
+			if !force && !tokenStore.NeedsRefresh(token) {
+				fmt.Printf("Token still valid for %dh, use --force to refresh anyway.\n",
+					int(time.Until(token.ExpiresAt).Hours()))
+				return nil
+			}
+
 			tokenResp, err := apiClient.RefreshToken(token.WorkspaceToken)
 			if err != nil {
 				return fmt.Errorf("refresh failed: %w", err)
@@ -175,6 +496,184 @@ func newAuthRefreshCommand() *cobra.Command {
 
 			fmt.Printf("Token refreshed. New expiry: %s\n",
 				newToken.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+
+			// Show the refreshed token's grants so scope changes are visible.
+			oldClaims, err := decodeJWTClaims(token.WorkspaceToken)
+			if err != nil {
+				oldClaims = map[string]interface{}{}
+			}
+			newClaims, err := decodeJWTClaims(newToken.WorkspaceToken)
+			if err != nil {
+				newClaims = map[string]interface{}{}
+			}
+
+			workspaceID := newToken.WorkspaceID
+			if v, ok := newClaims["workspace_id"].(string); ok && v != "" {
+				workspaceID = v
+			}
+			newScopes := claimScopes(newClaims)
+
+			fmt.Printf("  Workspace:  %s\n", workspaceID)
+			if len(newScopes) > 0 {
+				fmt.Printf("  Scopes:     %s\n", strings.Join(newScopes, ", "))
+			}
+			if diff := scopeDiff(claimScopes(oldClaims), newScopes); diff != "" {
+				fmt.Printf("  Changed:    %s\n", diff)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&force, "force", false, "Refresh even if the token is not near expiry")
+	cmd.Flags().BoolVar(&daemon, "daemon", false, "Run in the foreground, refreshing the token whenever it nears expiry")
+	cmd.MarkFlagsMutuallyExclusive("force", "daemon")
+	return cmd
+}
+
+// runRefreshDaemon runs the token refresher in the foreground until ctx is
+// cancelled or the process receives SIGTERM.
+func runRefreshDaemon(ctx context.Context) error {
+	// This process does the refreshing now; stop the background copy so
+	// the token isn't refreshed twice.
+	stopAutoRefresh()
+
+	ctx, stop := signal.NotifyContext(ctx, syscall.SIGTERM)
+	defer stop()
+
+	if token := tokenStore.Load(); token != nil {
+		logInfo("token refresh daemon started; token expires %s",
+			token.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+	} else {
+		logInfo("token refresh daemon started; waiting for `dea auth login`")
+	}
+
+	auth.RunAutoRefresh(ctx, tokenStore, refreshToken, func(t *auth.TokenData) {
+		logInfo("token refreshed; new expiry %s", t.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+	})
+
+	logInfo("token refresh daemon stopped")
+	return nil
+}
+
+func newAuthRevokeCommand() *cobra.Command {
+	var all bool
+
+	cmd := &cobra.Command{
+		Use:   "revoke",
+		Short: "Revoke the workspace token server-side and remove it locally",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			token := tokenStore.Load()
+			if token == nil {
+				return fmt.Errorf("not authenticated. Run `dea auth login`")
+			}
+
+			body := map[string]interface{}{
+				"token": token.WorkspaceToken,
+			}
+			if all {
+				body["all"] = true
+				body["agent_id"] = token.AgentID
+			}
+
+			_, err := apiClient.Post(api.PathTokenRevoke, body)
+			switch {
+			case errors.Is(err, api.ErrUnauthorized):
+				// Already rejected server-side; nothing left to revoke remotely.
+				fmt.Println("Token was already invalid server-side.")
+			case err != nil:
+				return fmt.Errorf("revoke failed (local token kept): %w", err)
+			case all:
+				fmt.Printf("Revoked all tokens for agent %s.\n", token.AgentID)
+			default:
+				fmt.Println("Token revoked.")
+			}
+
+			if err := tokenStore.Clear(); err != nil && !os.IsNotExist(err) {
+				return fmt.Errorf("failed to remove local token: %w", err)
+			}
+			fmt.Println("Local token removed.")
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&all, "all", false, "Revoke every token issued to this agent")
+	return cmd
+}
+
+func newAuthImportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "import [file]",
+		Short: "Store a token issued elsewhere (reads stdin when file is omitted or -)",
+		Long: `Store a pre-issued token without needing the agent secret. The input is the
+JSON returned by token-service (optionally wrapped in { data: ... }) or a
+copied tokens.json. The token must be a JWT whose exp claim is in the future.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			var (
+				data []byte
+				err  error
+			)
+			if len(args) == 0 || args[0] == "-" {
+				data, err = io.ReadAll(os.Stdin)
+			} else {
+				data, err = os.ReadFile(args[0])
+			}
+			if err != nil {
+				return fmt.Errorf("failed to read token: %w", err)
+			}
+
+			entry, err := unwrapObject(data)
+			if err != nil {
+				return fmt.Errorf("invalid token JSON: %w", err)
+			}
+			raw, err := json.Marshal(entry)
+			if err != nil {
+				return err
+			}
+			var token auth.TokenData
+			if err := json.Unmarshal(raw, &token); err != nil {
+				return fmt.Errorf("invalid token JSON: %w", err)
+			}
+			if token.WorkspaceToken == "" {
+				return fmt.Errorf("invalid token JSON: missing workspace_token")
+			}
+
+			claims, err := decodeJWTClaims(token.WorkspaceToken)
+			if err != nil {
+				return fmt.Errorf("workspace_token is not a valid JWT: %w", err)
+			}
+			exp, ok := claims["exp"].(float64)
+			if !ok {
+				return fmt.Errorf("workspace_token has no exp claim")
+			}
+			expiresAt := time.Unix(int64(exp), 0)
+			if !expiresAt.After(time.Now()) {
+				return fmt.Errorf("workspace_token expired at %s", expiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
+			}
+
+			// Fill gaps from the claims so status and refresh work as after login.
+			if token.ExpiresAt.IsZero() {
+				token.ExpiresAt = expiresAt
+			}
+			if token.AgentID == "" {
+				token.AgentID, _ = claims["agent_id"].(string)
+			}
+			if token.WorkspaceID == "" {
+				token.WorkspaceID, _ = claims["workspace_id"].(string)
+			}
+			if token.TokenType == "" {
+				token.TokenType = "Bearer"
+			}
+			if token.Endpoint == "" {
+				token.Endpoint = cfg.Endpoint
+			}
+
+			if err := tokenStore.Save(&token); err != nil {
+				return fmt.Errorf("failed to store token: %w", err)
+			}
+
+			fmt.Printf("Imported token for agent %s. Expires %s.\n",
+				token.AgentID, token.ExpiresAt.UTC().Format("2006-01-02 15:04:05 UTC"))
 			return nil
 		},
 	}
@@ -211,12 +710,115 @@ func decodeJWTClaims(token string) (map[string]interface{}, error) {
 	return claims, nil
 }
 
+// claimScopes extracts the "scopes" claim, which may be a list or a single
+// space/comma separated string.
+func claimScopes(claims map[string]interface{}) []string {
+	switch s := claims["scopes"].(type) {
+	case []interface{}:
+		scopes := make([]string, 0, len(s))
+		for _, scope := range s {
+			scopes = append(scopes, fmt.Sprintf("%v", scope))
+		}
+		return scopes
+	case string:
+		return strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == ' ' })
+	}
+	return nil
+}
+
+// scopeDiff renders added and removed scopes as "+a, -b". Returns "" when the
+// sets are equal.
+func scopeDiff(before, after []string) string {
+	had := make(map[string]bool, len(before))
+	for _, s := range before {
+		had[s] = true
+	}
+	has := make(map[string]bool, len(after))
+	for _, s := range after {
+		has[s] = true
+	}
+
+	var parts []string
+	for _, s := range after {
+		if !had[s] {
+			parts = append(parts, "+"+s)
+		}
+	}
+	for _, s := range before {
+		if !has[s] {
+			parts = append(parts, "-"+s)
+		}
+	}
+	return strings.Join(parts, ", ")
+}
+
+// agentIDFromToken returns the agent ID from the token's JWT claims, falling
+// back to the stored token metadata.
+func agentIDFromToken(token *auth.TokenData) string {
+	if claims, err := decodeJWTClaims(token.WorkspaceToken); err == nil {
+		if v, ok := claims["agent_id"].(string); ok && v != "" {
+			return v
+		}
+	}
+	return token.AgentID
+}
+
+// currentAgentID returns the agent commands act as: --agent-id, then
+// $DEA_AGENT_ID, then the token's own agent. When the token lists
+// allowed_agents, an override must be one of them.
+func currentAgentID(token *auth.TokenData) (string, error) {
+	override, source := agentIDFlag, "--agent-id"
+	if override == "" {
+		override, source = os.Getenv("DEA_AGENT_ID"), "DEA_AGENT_ID"
+	}
+	if override == "" {
+		return agentIDFromToken(token), nil
+	}
+
+	if !agentIDPattern.MatchString(override) {
+		return "", fmt.Errorf("%s: invalid agent ID %q", source, override)
+	}
+	claims, err := decodeJWTClaims(token.WorkspaceToken)
+	if err != nil {
+		return override, nil
+	}
+	allowed, ok := claims["allowed_agents"].([]interface{})
+	if !ok || override == agentIDFromToken(token) {
+		return override, nil
+	}
+	for _, a := range allowed {
+		if a == override {
+			return override, nil
+		}
+	}
+	return "", fmt.Errorf("%s: agent %q is not allowed by this token", source, override)
+}
+
 // mustLoadToken loads the token or exits with an error message.
 func mustLoadToken() *auth.TokenData {
 	token := tokenStore.Load()
 	if token == nil {
-		fmt.Fprintln(os.Stderr, "Not authenticated. Run `dea auth login`.")
+		logError("Not authenticated. Run `dea auth login`.")
 		os.Exit(1)
 	}
+	warnEndpointMismatch(token)
 	return token
 }
+
+// endpointMismatchWarned keeps warnEndpointMismatch to one warning per run.
+var endpointMismatchWarned bool
+
+// warnEndpointMismatch warns when requests are about to go to a different
+// endpoint than the token was issued for, e.g. a staging token sent to
+// production.
+func warnEndpointMismatch(token *auth.TokenData) {
+	if endpointMismatchWarned || token.Endpoint == "" {
+		return
+	}
+	if strings.TrimRight(token.Endpoint, "/") == strings.TrimRight(cfg.Endpoint, "/") {
+		return
+	}
+	endpointMismatchWarned = true
+	logWarn("token was issued for %s but requests go to %s (pass --endpoint-from-token to use the token's endpoint)",
+		token.Endpoint, cfg.Endpoint)
+}
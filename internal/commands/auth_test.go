@@ -0,0 +1,37 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+func TestIssueTokenWithRetryStopsOnCancel(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + l.Addr().String()
+	l.Close()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	old := apiClient
+	apiClient = api.NewClient(endpoint, 5, staticToken(""), api.WithContext(ctx))
+	defer func() { apiClient = old }()
+
+	// The first attempt fails to connect; Ctrl-C arrives during the backoff.
+	time.AfterFunc(100*time.Millisecond, cancel)
+	start := time.Now()
+	_, err = issueTokenWithRetry(ctx, map[string]string{"agent_id": "a"}, loginRetries)
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if elapsed := time.Since(start); elapsed >= loginRetryDelay {
+		t.Errorf("took %v, want the backoff cut short", elapsed)
+	}
+}
@@ -1,10 +1,15 @@
 package commands
 
 import (
-	"encoding/json"
+	"bufio"
+	"context"
+	"errors"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/poll"
 	"github.com/spf13/cobra"
 )
 
@@ -34,7 +39,13 @@ func newAutoListCommand() *cobra.Command {
 }
 
 func newAutoRunCommand() *cobra.Command {
-	return &cobra.Command{
+	var (
+		followLogs bool
+		wait       bool
+		interval   time.Duration
+	)
+
+	cmd := &cobra.Command{
 		Use:   "run <automation-id>",
 		Short: "Execute an automation",
 		Args:  cobra.ExactArgs(1),
@@ -42,28 +53,159 @@ func newAutoRunCommand() *cobra.Command {
 			automationID := args[0]
 			mustLoadToken()
 
+			if (followLogs || wait) && interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+
 			data, err := apiClient.Post(api.AutomationRunPath(automationID), map[string]string{})
 			if err != nil {
-				if isNetworkErr(err) {
+				if api.IsNetworkError(err) {
 					return err
 				}
 				return fmt.Errorf("failed to run automation %s: %w", automationID, err)
 			}
 
-			var resp map[string]interface{}
-			if len(data) > 0 {
-				if err := json.Unmarshal(data, &resp); err == nil {
-					if msg, ok := resp["message"].(string); ok {
-						fmt.Println(msg)
-						return nil
-					}
-				}
+			resp, _ := unwrapObject(data)
+			if msg := strField(resp, "message", ""); msg != "" {
+				fmt.Println(msg)
+			} else {
+				fmt.Printf("Automation %s executed.\n", automationID)
 			}
 
-			fmt.Printf("Automation %s executed.\n", automationID)
-			return nil
+			if !followLogs && !wait {
+				return nil
+			}
+			runID := strField(resp, "run_id", strField(resp, "id", ""))
+			if runID == "" {
+				return fmt.Errorf("server did not return a run ID; cannot follow automation %s", automationID)
+			}
+
+			ctx := cmd.Context()
+			if followLogs {
+				err := followRunLogs(ctx, automationID, runID)
+				switch {
+				case errors.Is(err, api.ErrStreamingUnsupported):
+					logInfo("Log streaming not supported; polling run status instead.")
+				case err != nil:
+					return err
+				case ctx.Err() != nil:
+					logInfo("Stopped following run %s; it continues on the server.", runID)
+					return nil
+				}
+			}
+			// After a log stream this usually finds the run already finished.
+			return waitForRun(ctx, automationID, runID, interval)
 		},
 	}
+
+	cmd.Flags().BoolVar(&followLogs, "follow-logs", false, "Stream the run's logs until it completes")
+	cmd.Flags().BoolVar(&wait, "wait", false, "Poll until the run completes and report its final status")
+	cmd.Flags().DurationVar(&interval, "interval", 5*time.Second, "Polling interval for --wait")
+	return cmd
+}
+
+// followRunLogs prints a run's log stream as it arrives, until the stream
+// ends, an SSE "end" event arrives, or ctx is done. Server-sent event
+// "data:" lines are printed without the prefix; other lines are printed
+// as-is. Returns api.ErrStreamingUnsupported when the caller should poll.
+func followRunLogs(ctx context.Context, automationID, runID string) error {
+	body, err := apiClient.Stream(ctx, api.AutomationRunLogsPath(automationID, runID))
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	scanner := bufio.NewScanner(body)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "" || strings.HasPrefix(line, ":"):
+			// SSE event separator or comment/keepalive.
+		case strings.HasPrefix(line, "event:"):
+			if strings.TrimSpace(strings.TrimPrefix(line, "event:")) == "end" {
+				return nil
+			}
+		case strings.HasPrefix(line, "data:"):
+			fmt.Println(strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		case strings.HasPrefix(line, "id:"), strings.HasPrefix(line, "retry:"):
+		default:
+			fmt.Println(line)
+		}
+	}
+	if err := scanner.Err(); err != nil && ctx.Err() == nil {
+		return fmt.Errorf("log stream interrupted: %w", err)
+	}
+	return nil
+}
+
+// runFinalStates maps terminal run statuses to whether the run succeeded.
+var runFinalStates = map[string]bool{
+	"succeeded": true,
+	"success":   true,
+	"completed": true,
+	"failed":    false,
+	"error":     false,
+	"cancelled": false,
+	"canceled":  false,
+}
+
+// fetchRunStatus returns the status field of a run.
+func fetchRunStatus(automationID, runID string) (string, error) {
+	data, err := apiClient.Get(api.AutomationRunStatusPath(automationID, runID))
+	if err != nil {
+		return "", fmt.Errorf("failed to get run %s: %w", runID, err)
+	}
+	run, err := unwrapObject(data, "run")
+	if err != nil {
+		return "", fmt.Errorf("unexpected run response: %w", err)
+	}
+	return strings.ToLower(strField(run, "status", "")), nil
+}
+
+// waitForRun polls a run until it reaches a final state or ctx is done.
+func waitForRun(ctx context.Context, automationID, runID string, interval time.Duration) error {
+	waiting, last, final := false, "", ""
+	err := poll.Until(ctx, poll.Options{Interval: interval, Immediate: true}, func(context.Context) (bool, error) {
+		status, err := fetchRunStatus(automationID, runID)
+		if err != nil {
+			if !api.IsRetryable(err) {
+				return false, err
+			}
+			logWarn("%v", err)
+			return false, nil
+		}
+		if _, ok := runFinalStates[status]; ok {
+			final = status
+			return true, nil
+		}
+		if !waiting {
+			fmt.Printf("Waiting for run %s (Ctrl-C to stop)...\n", runID)
+			waiting = true
+		}
+		if status != last {
+			fmt.Printf("%s  %s\n", time.Now().Format("15:04:05"), status)
+			last = status
+		}
+		return false, nil
+	})
+	switch {
+	case ctx.Err() != nil:
+		logInfo("Stopped waiting for run %s; it continues on the server.", runID)
+		return nil
+	case err != nil:
+		return err
+	}
+	return runOutcome(runID, final)
+}
+
+// runOutcome reports a run's final status, as an error when it failed.
+func runOutcome(runID, status string) error {
+	if !runFinalStates[status] {
+		return fmt.Errorf("run %s %s", runID, status)
+	}
+	fmt.Printf("Run %s %s.\n", runID, status)
+	return nil
 }
 
 func newAutoInspectCommand() *cobra.Command {
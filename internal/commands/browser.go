@@ -0,0 +1,56 @@
+package commands
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+)
+
+// cardDashboardURL returns the web dashboard page for cardID, built from
+// the dashboard_url config.
+func cardDashboardURL(cardID string) (string, error) {
+	base := strings.TrimRight(strings.TrimSpace(cfg.DashboardURL), "/")
+	if base == "" {
+		return "", fmt.Errorf("dashboard_url is not set. Add it to config.toml, e.g. dashboard_url = \"https://app.example.com/cards\"")
+	}
+	if _, err := url.Parse(base); err != nil {
+		return "", fmt.Errorf("invalid dashboard_url %q: %w", cfg.DashboardURL, err)
+	}
+	return base + "/" + url.PathEscape(cardID), nil
+}
+
+// openCardInDashboard opens cardID's dashboard page in the default browser.
+// When no browser can be launched, e.g. over SSH, the URL is printed for
+// the user to open themselves instead of failing the command.
+func openCardInDashboard(cardID string) error {
+	link, err := cardDashboardURL(cardID)
+	if err != nil {
+		return err
+	}
+	if err := openBrowser(link); err != nil {
+		logWarn("could not open a browser (%v). Open %s", err, link)
+		return nil
+	}
+	logInfo("opened %s", link)
+	return nil
+}
+
+// openBrowser opens link with the platform's URL handler.
+func openBrowser(link string) error {
+	var cmd *exec.Cmd
+	switch runtime.GOOS {
+	case "darwin":
+		cmd = exec.Command("open", link)
+	case "windows":
+		cmd = exec.Command("rundll32", "url.dll,FileProtocolHandler", link)
+	default:
+		if os.Getenv("DISPLAY") == "" && os.Getenv("WAYLAND_DISPLAY") == "" {
+			return fmt.Errorf("no display")
+		}
+		cmd = exec.Command("xdg-open", link)
+	}
+	return cmd.Run()
+}
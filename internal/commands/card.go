@@ -0,0 +1,402 @@
+package commands
+
+import (
+	"fmt"
+	"net/http"
+	"os"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newCardCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "card",
+		Short: "Collaborate on cards",
+	}
+
+	cmd.AddCommand(newCardCommentCommand())
+	cmd.AddCommand(newCardAssignCommand())
+	cmd.AddCommand(newCardHistoryCommand())
+	cmd.AddCommand(newCardLinkCommand())
+	cmd.AddCommand(newCardSearchCommand())
+
+	return cmd
+}
+
+func newCardCommentCommand() *cobra.Command {
+	var (
+		text     string
+		fromFile string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "comment <card-id>",
+		Short: "Leave a comment on a card",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cardID, err := resolveCardID(args[0])
+			if err != nil {
+				return err
+			}
+
+			if (text == "") == (fromFile == "") {
+				return fmt.Errorf("provide exactly one of --text or --from-file")
+			}
+			if fromFile != "" {
+				data, err := os.ReadFile(fromFile)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", fromFile, err)
+				}
+				text = string(data)
+			}
+			text = strings.TrimSpace(text)
+			if text == "" {
+				return fmt.Errorf("comment is empty")
+			}
+
+			agentID, err := currentAgentID(mustLoadToken())
+			if err != nil {
+				return err
+			}
+
+			body := map[string]string{
+				"agent_id": agentID,
+				"content":  text,
+			}
+
+			data, queued, err := apiPost(api.CardCommentsPath(cardID), body)
+			if err != nil {
+				return fmt.Errorf("failed to comment on card %s: %w", cardID, err)
+			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
+
+			// Echo the new comment's ID from { data: { id } } or { id }.
+			if comment, err := unwrapObject(data); err == nil {
+				if id := strField(comment, "id", ""); id != "" {
+					fmt.Printf("Comment %s added to card %s.\n", id, cardID)
+					return nil
+				}
+			}
+
+			fmt.Printf("Comment added to card %s.\n", cardID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&text, "text", "", "Comment text")
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the comment text from a file")
+	return cmd
+}
+
+// agentIDPattern matches agent IDs: a leading alphanumeric followed by up to
+// 127 alphanumerics, dots, dashes, underscores, or colons.
+var agentIDPattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._:-]{0,127}$`)
+
+func newCardAssignCommand() *cobra.Command {
+	var agentID string
+
+	cmd := &cobra.Command{
+		Use:   "assign <card-id>",
+		Short: "Assign a card to a specific agent",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cardID, err := resolveCardID(args[0])
+			if err != nil {
+				return err
+			}
+
+			if agentID == "" {
+				return fmt.Errorf("--agent is required")
+			}
+			if !agentIDPattern.MatchString(agentID) {
+				return fmt.Errorf("invalid agent ID %q", agentID)
+			}
+
+			mustLoadToken()
+
+			body := map[string]string{
+				"agent_id": agentID,
+			}
+
+			_, queued, err := apiPost(api.CardAssignPath(cardID), body)
+			if err != nil {
+				return fmt.Errorf("failed to assign card %s: %w", cardID, err)
+			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
+
+			fmt.Printf("Assigned %s to %s.\n", cardID, agentID)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&agentID, "agent", "", "Agent ID to assign the card to")
+	return cmd
+}
+
+func newCardHistoryCommand() *cobra.Command {
+	var out outputOptions
+
+	cmd := &cobra.Command{
+		Use:   "history <card-id>",
+		Short: "Show a card's lane history",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cardID, err := resolveCardID(args[0])
+			if err != nil {
+				return err
+			}
+			mustLoadToken()
+
+			data, err := apiClient.Get(api.CardHistoryPath(cardID))
+			if err != nil {
+				return handleAPIError(err, "card", cardID, "get history for")
+			}
+
+			events, err := unwrapList(data, "history")
+			if err != nil {
+				return fmt.Errorf("unexpected history response: %w", err)
+			}
+			sortHistory(events)
+
+			if ok, err := out.render(events); ok || err != nil {
+				return err
+			}
+
+			if len(events) == 0 {
+				fmt.Printf("No history for card %s.\n", cardID)
+				return nil
+			}
+
+			for _, e := range events {
+				from := strField(e, "from_lane", strField(e, "from", ""))
+				to := strField(e, "to_lane", strField(e, "to", "?"))
+				change := to
+				if from != "" {
+					change = from + " -> " + to
+				}
+
+				line := fmt.Sprintf("%-19s  %s", historyTime(e), change)
+				if actor := strField(e, "actor", strField(e, "agent_id", "")); actor != "" {
+					line += "  by " + actor
+				}
+				fmt.Println(line)
+			}
+			return nil
+		},
+	}
+
+	addOutputFlags(cmd, &out)
+	return cmd
+}
+
+// historyTimestamp parses the time of a history event, which the server
+// sends as created_at or timestamp.
+func historyTimestamp(e map[string]interface{}) (time.Time, bool) {
+	raw := strField(e, "created_at", strField(e, "timestamp", ""))
+	t, err := time.Parse(time.RFC3339, raw)
+	return t, err == nil
+}
+
+// historyTime formats an event's time in local time, or "?" if missing.
+func historyTime(e map[string]interface{}) string {
+	t, ok := historyTimestamp(e)
+	if !ok {
+		return strField(e, "created_at", strField(e, "timestamp", "?"))
+	}
+	return t.Local().Format("2006-01-02 15:04:05")
+}
+
+// sortHistory orders events oldest first. Events without a parseable time
+// keep their server order.
+func sortHistory(events []map[string]interface{}) {
+	sort.SliceStable(events, func(i, j int) bool {
+		ti, okI := historyTimestamp(events[i])
+		tj, okJ := historyTimestamp(events[j])
+		return okI && okJ && ti.Before(tj)
+	})
+}
+
+// cardRelations maps `card link` flags to the relation names the API uses.
+var cardRelations = []struct {
+	flag     string
+	relation string
+	usage    string
+}{
+	{"blocks", "blocks", "Card that this card blocks"},
+	{"blocked-by", "blocked_by", "Card that blocks this card"},
+	{"relates-to", "relates_to", "Card that this card relates to"},
+}
+
+func newCardLinkCommand() *cobra.Command {
+	targets := make([]string, len(cardRelations))
+
+	cmd := &cobra.Command{
+		Use:   "link <card-id>",
+		Short: "Record a relationship between two cards",
+		Long: `Record that a card blocks, is blocked by, or relates to another card.
+Give exactly one of --blocks, --blocked-by or --relates-to.`,
+		Example: `  # The current card can't finish until card-456 does
+  dea card link current --blocked-by card-456
+
+  # Note a related card
+  dea card link card-123 --relates-to card-789`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			cardID, err := resolveCardID(args[0])
+			if err != nil {
+				return err
+			}
+
+			relation, other := "", ""
+			for i, r := range cardRelations {
+				if targets[i] == "" {
+					continue
+				}
+				if relation != "" {
+					return fmt.Errorf("give only one of --blocks, --blocked-by or --relates-to")
+				}
+				relation, other = r.relation, targets[i]
+			}
+			if relation == "" {
+				return fmt.Errorf("one of --blocks, --blocked-by or --relates-to is required")
+			}
+			if other == cardID {
+				return fmt.Errorf("cannot link card %s to itself", cardID)
+			}
+
+			mustLoadToken()
+
+			body := map[string]string{
+				"target_card_id": other,
+				"relation":       relation,
+			}
+
+			_, queued, err := apiPost(api.CardLinksPath(cardID), body)
+			if err != nil {
+				return fmt.Errorf("failed to link card %s: %w", cardID, err)
+			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
+
+			fmt.Printf("Linked %s %s %s.\n", cardID, strings.ReplaceAll(relation, "_", " "), other)
+			return nil
+		},
+	}
+
+	for i, r := range cardRelations {
+		cmd.Flags().StringVar(&targets[i], r.flag, "", r.usage)
+	}
+	return cmd
+}
+
+func newCardSearchCommand() *cobra.Command {
+	var (
+		projectSlug string
+		lane        string
+		out         outputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "search <query>",
+		Short: "Find cards whose title or description contains a query",
+		Long: `Find active cards whose title or description contains the query,
+ignoring case. Matches in titles are highlighted when output is a terminal.`,
+		Example: `  # Find cards mentioning the uploader
+  dea card search uploader
+
+  # Only look in review on another project
+  dea card search "rate limit" --project infra --lane review`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			query := strings.TrimSpace(args[0])
+			if query == "" {
+				return fmt.Errorf("search query is empty")
+			}
+			mustLoadToken()
+
+			projectID, err := resolveProject(projectSlug)
+			if err != nil {
+				return err
+			}
+			cards, err := fetchBoard(projectID)
+			if err != nil {
+				if api.StatusCode(err) == http.StatusNotFound {
+					return fmt.Errorf("project %q not found", projectID)
+				}
+				return handleAPIError(err, "board", projectID, "list")
+			}
+
+			needle := strings.ToLower(query)
+			cards = filterCards(cards, func(c map[string]interface{}) bool {
+				if lane != "" && !cardInLane(c, lane) {
+					return false
+				}
+				return strings.Contains(strings.ToLower(strField(c, "title", "")), needle) ||
+					strings.Contains(strings.ToLower(strField(c, "description", "")), needle)
+			})
+
+			if ok, err := out.render(cards); ok || err != nil {
+				return err
+			}
+			if len(cards) == 0 {
+				fmt.Printf("No cards match %q.\n", query)
+				return nil
+			}
+			if !isTerminal(os.Stdout) || os.Getenv("NO_COLOR") != "" {
+				query = ""
+			}
+			printCardTableHighlighting(cards, query)
+			return nil
+		},
+	}
+
+	addOutputFlags(cmd, &out)
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Project slug or ID (default: $DEA_PROJECT, then default_project in config)")
+	cmd.Flags().StringVar(&lane, "lane", "", "Only search cards in this lane")
+	return cmd
+}
+
+const (
+	highlightStart = "\033[1;33m"
+	highlightEnd   = "\033[0m"
+)
+
+// highlightMatches wraps each case-insensitive occurrence of query in s in
+// terminal highlight codes.
+func highlightMatches(s, query string) string {
+	if query == "" {
+		return s
+	}
+	// Lowercasing can change byte lengths outside ASCII, which would put
+	// the indexes below out of step with s.
+	lower, needle := strings.ToLower(s), strings.ToLower(query)
+	if len(lower) != len(s) || len(needle) != len(query) {
+		return s
+	}
+
+	var b strings.Builder
+	for {
+		i := strings.Index(lower, needle)
+		if i < 0 {
+			b.WriteString(s)
+			return b.String()
+		}
+		b.WriteString(s[:i])
+		b.WriteString(highlightStart + s[i:i+len(needle)] + highlightEnd)
+		s, lower = s[i+len(needle):], lower[i+len(needle):]
+	}
+}
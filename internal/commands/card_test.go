@@ -0,0 +1,84 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/auth"
+	"github.com/dea-exmachina/dea-cli/internal/config"
+)
+
+// withLoggedIn sets up a signed-in session against a fake API that answers
+// every request with an empty history, and returns the paths requested.
+func withLoggedIn(t *testing.T) func() []string {
+	t.Helper()
+	t.Setenv("DEA_HOME", t.TempDir())
+
+	var (
+		mu    sync.Mutex
+		paths []string
+	)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		mu.Lock()
+		paths = append(paths, r.URL.Path)
+		mu.Unlock()
+		w.Header().Set("Content-Type", "application/json")
+		w.Write([]byte(`{"data":{"history":[]}}`))
+	}))
+
+	oldCfg, oldStore, oldClient, oldDir := cfg, tokenStore, apiClient, contextDir
+	cfg = &config.Config{Endpoint: srv.URL}
+	tokenStore = auth.NewTokenStore()
+	apiClient = api.NewClient(srv.URL, 5, tokenStore)
+	contextDir = filepath.Join(t.TempDir(), ".dea-context")
+	t.Cleanup(func() {
+		srv.Close()
+		cfg, tokenStore, apiClient, contextDir = oldCfg, oldStore, oldClient, oldDir
+	})
+
+	if err := tokenStore.Save(&auth.TokenData{WorkspaceToken: "tok", ExpiresAt: time.Now().Add(time.Hour)}); err != nil {
+		t.Fatal(err)
+	}
+	return func() []string {
+		mu.Lock()
+		defer mu.Unlock()
+		return append([]string(nil), paths...)
+	}
+}
+
+func TestCardCommandsResolveCurrent(t *testing.T) {
+	for _, tc := range []struct {
+		args []string
+		want string
+	}{
+		{[]string{"comment", "current", "--text", "looks good"}, api.CardPath("c42") + "/comments"},
+		{[]string{"assign", "current", "--agent", "agent-7"}, api.CardPath("c42") + "/assign"},
+		{[]string{"history", "current"}, api.CardHistoryPath("c42")},
+	} {
+		t.Run(tc.args[0], func(t *testing.T) {
+			requested := withLoggedIn(t)
+			if err := ensureContextDir(); err != nil {
+				t.Fatal(err)
+			}
+			if err := os.WriteFile(currentCardPath(), []byte("c42\n"), 0644); err != nil {
+				t.Fatal(err)
+			}
+
+			cmd := newCardCommand()
+			cmd.SetArgs(tc.args)
+			if err := cmd.Execute(); err != nil {
+				t.Fatal(err)
+			}
+			paths := requested()
+			if len(paths) != 1 || paths[0] != tc.want {
+				t.Errorf("requested %v, want %s", paths, tc.want)
+			}
+		})
+	}
+}
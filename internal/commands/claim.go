@@ -2,29 +2,93 @@ package commands
 
 import (
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
 	"os"
+	"strings"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
 	"github.com/spf13/cobra"
 )
 
+// claimableLanes are the lanes a card can be claimed from.
+var claimableLanes = []string{"backlog", "ready"}
+
 func newClaimCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "claim <card-id>",
+	var (
+		dryRun bool
+		steal  bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "claim [card-id]",
 		Short: "Claim a card and set it as in-progress",
-		Args:  cobra.ExactArgs(1),
+		Long: "Claim a card and set it as in-progress.\n\n" +
+			"When the card ID is omitted on a terminal, pick a claimable card from the board.",
+		Example: `  # Claim a card and make it the current card
+  dea claim card-123
+
+  # Check that a claim would succeed without taking the card
+  dea claim card-123 --dry-run
+
+  # Take over a card another agent holds (if your token allows it)
+  dea claim card-123 --steal
+
+  # Pick a claimable card from the board interactively
+  dea claim`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cardID := args[0]
 			token := mustLoadToken()
 
-			body := map[string]string{
-				"agent_id": token.AgentID,
+			cardID, err := cardIDArg(args, func(c map[string]interface{}) bool {
+				for _, l := range claimableLanes {
+					if cardInLane(c, l) {
+						return true
+					}
+				}
+				return false
+			})
+			if err != nil {
+				return err
+			}
+
+			agentID, err := currentAgentID(token)
+			if err != nil {
+				return err
+			}
+
+			if dryRun {
+				return checkClaimable(cardID, agentID)
+			}
+
+			body := map[string]interface{}{
+				"agent_id": agentID,
 			}
 
 			data, err := apiClient.Post(api.CardClaimPath(cardID), body)
+			if api.StatusCode(err) == http.StatusConflict {
+				holder := conflictAssignee(cardID, err)
+				switch {
+				case holder == agentID:
+					// Claiming a card we already hold is a no-op, so a
+					// retried claim still succeeds.
+					err = nil
+				case !steal:
+					return fmt.Errorf("card %s is already claimed by %s (use --steal to take it over)", cardID, holder)
+				default:
+					body["force"] = true
+					data, err = apiClient.Post(api.CardClaimPath(cardID), body)
+					if api.StatusCode(err) == http.StatusForbidden {
+						return fmt.Errorf("not permitted to steal card %s from %s: %w", cardID, holder, err)
+					}
+					if err == nil {
+						fmt.Printf("Took over %s from %s.\n", cardID, holder)
+					}
+				}
+			}
 			if err != nil {
-				if isNetworkErr(err) {
+				if api.IsNetworkError(err) {
 					return err
 				}
 				return fmt.Errorf("failed to claim card %s: %w", cardID, err)
@@ -36,12 +100,12 @@ func newClaimCommand() *cobra.Command {
 				_ = json.Unmarshal(data, &resp)
 			}
 
-			// Write current card to .dea-context/.current-card
-			if err := os.MkdirAll(".dea-context", 0755); err != nil {
-				fmt.Fprintf(os.Stderr, "warning: could not create .dea-context dir: %v\n", err)
+			// Record the current card in the context directory.
+			if err := ensureContextDir(); err != nil {
+				logWarn("could not record current card: %v", err)
 			} else {
-				if err := os.WriteFile(".dea-context/.current-card", []byte(cardID), 0644); err != nil {
-					fmt.Fprintf(os.Stderr, "warning: could not write .current-card: %v\n", err)
+				if err := os.WriteFile(currentCardPath(), []byte(cardID), 0644); err != nil {
+					logWarn("could not write .current-card: %v", err)
 				}
 			}
 
@@ -49,19 +113,78 @@ func newClaimCommand() *cobra.Command {
 			return nil
 		},
 	}
+
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Check whether the card can be claimed without claiming it")
+	cmd.Flags().BoolVar(&steal, "steal", false, "Reassign the card to you if another agent holds it (requires permission)")
+	return cmd
 }
 
-func isNetworkErr(err error) bool {
-	if err == nil {
-		return false
+// conflictAssignee names the agent holding cardID after a 409 claim
+// response: from the error body if the API included it, otherwise by
+// fetching the card.
+func conflictAssignee(cardID string, err error) string {
+	var apiErr *api.APIError
+	if errors.As(err, &apiErr) {
+		var body map[string]interface{}
+		if json.Unmarshal(apiErr.Body, &body) == nil {
+			if inner, ok := body["error"].(map[string]interface{}); ok {
+				body = inner
+			}
+			if a := cardAssignee(body); a != "" {
+				return a
+			}
+		}
 	}
-	msg := err.Error()
-	for _, keyword := range []string{"network error", "connection", "no such host", "timeout", "dial"} {
-		if containsIgnoreCase(msg, keyword) {
-			return true
+
+	if data, err := apiClient.Get(api.CardContextPath(cardID)); err == nil {
+		if card, err := extractCard(data); err == nil {
+			if a := cardAssignee(card); a != "" {
+				return a
+			}
 		}
 	}
-	return false
+	return "another agent"
+}
+
+// checkClaimable fetches a card and reports whether agentID could claim it:
+// the card must be in a claimable lane and unassigned or already agentID's.
+// Nothing is written.
+func checkClaimable(cardID, agentID string) error {
+	data, err := apiClient.Get(api.CardContextPath(cardID))
+	if err != nil {
+		return handleAPIError(err, "card", cardID, "get")
+	}
+	card, err := extractCard(data)
+	if err != nil {
+		return fmt.Errorf("invalid context for card %s: %w", cardID, err)
+	}
+
+	var problems []string
+	lane := strField(card, "lane", strField(card, "status", ""))
+	claimable := false
+	for _, l := range claimableLanes {
+		if cardInLane(card, l) {
+			claimable = true
+			break
+		}
+	}
+	if !claimable {
+		problems = append(problems, fmt.Sprintf("card is in lane %q (claimable from: %s)", lane, strings.Join(claimableLanes, ", ")))
+	}
+	if assignee := cardAssignee(card); assignee != "" && assignee != agentID {
+		problems = append(problems, fmt.Sprintf("card is already claimed by %s", assignee))
+	}
+
+	if len(problems) > 0 {
+		fmt.Printf("Claim of %s would fail:\n", cardID)
+		for _, p := range problems {
+			fmt.Printf("  - %s\n", p)
+		}
+		return fmt.Errorf("card %s is not claimable", cardID)
+	}
+
+	fmt.Printf("Card %s (%s) can be claimed. No changes made.\n", cardID, strField(card, "title", "(no title)"))
+	return nil
 }
 
 func containsIgnoreCase(s, sub string) bool {
@@ -0,0 +1,53 @@
+package commands
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// defaultContextDir holds pulled card context, the current card and staged
+// artifacts unless context_dir is set. It is relative, so each working
+// directory has its own.
+const defaultContextDir = ".dea-context"
+
+// contextDir is the resolved context directory, set by initGlobals.
+var contextDir = defaultContextDir
+
+// resolveContextDir returns the context directory: configured (with a
+// leading ~ expanded) plus a subdirectory for the workspace, or
+// defaultContextDir when nothing is configured.
+func resolveContextDir(configured, workspaceID string) (string, error) {
+	if configured == "" {
+		return defaultContextDir, nil
+	}
+	dir := configured
+	if dir == "~" || strings.HasPrefix(dir, "~/") {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("context_dir: %w", err)
+		}
+		dir = filepath.Join(home, dir[1:])
+	}
+	if !filepath.IsAbs(dir) {
+		return "", fmt.Errorf("context_dir must be an absolute path, got %q", configured)
+	}
+	if workspaceID != "" {
+		dir = filepath.Join(dir, workspaceID)
+	}
+	return dir, nil
+}
+
+// contextPath returns the path of name inside the context directory.
+func contextPath(name string) string {
+	return filepath.Join(contextDir, name)
+}
+
+// ensureContextDir creates the context directory if it doesn't exist.
+func ensureContextDir() error {
+	if err := os.MkdirAll(contextDir, 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", contextDir, err)
+	}
+	return nil
+}
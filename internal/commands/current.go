@@ -0,0 +1,100 @@
+package commands
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+// currentCardKeyword may be given wherever a card ID is accepted to mean
+// the current card.
+const currentCardKeyword = "current"
+
+// currentCardPath holds the card set by `dea claim`.
+func currentCardPath() string {
+	return contextPath(".current-card")
+}
+
+// currentCardUsed is the card this command read from currentCardPath, if
+// any, so a 404 can be blamed on a stale pointer rather than shown raw.
+var currentCardUsed string
+
+// readCurrentCard returns the card set by `dea claim`.
+func readCurrentCard() (string, error) {
+	data, err := os.ReadFile(currentCardPath())
+	if err != nil && !os.IsNotExist(err) {
+		return "", fmt.Errorf("failed to read current card: %w", err)
+	}
+	cardID := strings.TrimSpace(string(data))
+	if cardID == "" {
+		return "", fmt.Errorf("no current card set. Use `dea claim <card-id>` first")
+	}
+	currentCardUsed = cardID
+	return cardID, nil
+}
+
+// resolveCardID expands the "current" keyword to the current card and
+// returns any other ID unchanged.
+func resolveCardID(cardID string) (string, error) {
+	if cardID == currentCardKeyword {
+		return readCurrentCard()
+	}
+	return cardID, nil
+}
+
+// cardFlagOrCurrent resolves a --card flag that defaults to the current card.
+func cardFlagOrCurrent(flagValue string) (string, error) {
+	if flagValue == "" {
+		if cardID, err := readCurrentCard(); err == nil {
+			return cardID, nil
+		}
+		return "", fmt.Errorf("--card is required (or run `dea claim <card-id>` first)")
+	}
+	return resolveCardID(flagValue)
+}
+
+// staleCurrentCardError is reported when a command that fell back to the
+// current card got a 404: the card was most likely deleted or archived
+// since it was claimed.
+type staleCurrentCardError struct {
+	cardID string
+	err    error
+}
+
+func (e *staleCurrentCardError) Error() string {
+	return fmt.Sprintf("current card %s no longer exists; run `dea claim <card-id>` again", e.cardID)
+}
+
+func (e *staleCurrentCardError) Unwrap() error {
+	return e.err
+}
+
+// explainStaleCurrentCard replaces a 404 from a command that used the
+// current card with a staleCurrentCardError.
+func explainStaleCurrentCard(err error) error {
+	if currentCardUsed == "" || api.StatusCode(err) != http.StatusNotFound {
+		return err
+	}
+	return &staleCurrentCardError{cardID: currentCardUsed, err: err}
+}
+
+// offerClearCurrentCard asks, when running interactively, whether to remove
+// the stale pointer behind a staleCurrentCardError.
+func offerClearCurrentCard(err error) {
+	var stale *staleCurrentCardError
+	if !errors.As(err, &stale) || !isTerminal(os.Stdin) {
+		return
+	}
+	if ok, _ := confirm(fmt.Sprintf("Clear current card %s?", stale.cardID)); !ok {
+		return
+	}
+	if err := os.Remove(currentCardPath()); err != nil && !os.IsNotExist(err) {
+		logWarn("failed to clear current card: %v", err)
+		return
+	}
+	fmt.Println("Current card cleared.")
+}
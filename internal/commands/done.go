@@ -1,7 +1,10 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
+	"io"
 	"os"
 	"strings"
 
@@ -9,17 +12,84 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// doneSummary is the --json result of `dea done`.
+type doneSummary struct {
+	CardID          string `json:"card_id"`
+	ArtifactsPushed int    `json:"artifacts_pushed"`
+	ArtifactsFailed int    `json:"artifacts_failed"`
+	// Transition is "success", "queued", "rejected" (governance) or "failed".
+	Transition    string `json:"transition"`
+	SignalEmitted bool   `json:"signal_emitted"`
+	SignalQueued  bool   `json:"signal_queued,omitempty"`
+}
+
 func newDoneCommand() *cobra.Command {
-	var summary string
+	var (
+		summary     string
+		summaryFile string
+		signalType  string
+		noSignal    bool
+		jsonOut     bool
+	)
 
 	cmd := &cobra.Command{
 		Use:   "done <card-id>",
 		Short: "Mark a card as done: push artifacts, transition to review, emit signal",
-		Args:  cobra.ExactArgs(1),
+		Example: `  # Push staged artifacts, move the card to review, and record a summary
+  dea done card-123 --summary "Added retry to the uploader"
+
+  # Finish the current card
+  dea done current
+
+  # Record the summary as a discovery signal instead of a pattern
+  dea done card-123 --summary "Uploader drops files over 2GB" --signal-type discovery
+
+  # Use a generated markdown report as the summary
+  dea done card-123 --summary-file report.md
+
+  # Finish without emitting a signal
+  dea done card-123 --summary "Added retry to the uploader" --no-signal
+
+  # Print a JSON summary for scripts instead of progress text
+  dea done card-123 --json`,
+		Args: cobra.ExactArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cardID := args[0]
+			if !isValidSignalType(signalType) {
+				return fmt.Errorf("invalid --signal-type %q. Valid types: %s",
+					signalType, strings.Join(validSignalTypes, ", "))
+			}
+			if summaryFile != "" {
+				var (
+					data []byte
+					err  error
+				)
+				if summaryFile == "-" {
+					data, err = io.ReadAll(os.Stdin)
+				} else {
+					data, err = os.ReadFile(summaryFile)
+				}
+				if err != nil {
+					return fmt.Errorf("failed to read summary: %w", err)
+				}
+				summary = string(data)
+			}
+			cardID, err := resolveCardID(args[0])
+			if err != nil {
+				return err
+			}
 			token := mustLoadToken()
 
+			// With --json, stdout carries only the summary and progress
+			// goes to stderr.
+			say := func(format string, args ...interface{}) {
+				if jsonOut {
+					logInfo(strings.TrimSpace(format), args...)
+					return
+				}
+				fmt.Printf(format, args...)
+			}
+			result := doneSummary{CardID: cardID}
+
 			// Step 1: Push staged artifacts if any exist.
 			staged, err := loadStagedArtifacts()
 			if err == nil {
@@ -32,80 +102,126 @@ func newDoneCommand() *cobra.Command {
 				}
 
 				if hasStagedForCard {
-					fmt.Printf("Pushing staged artifacts for card %s...\n", cardID)
+					say("Pushing staged artifacts for card %s...\n", cardID)
 					var toPush []StagedArtifact
-					var remaining []StagedArtifact
 					for _, a := range staged {
 						if a.CardID == cardID {
 							toPush = append(toPush, a)
-						} else {
-							remaining = append(remaining, a)
 						}
 					}
 
-					pushedCount := 0
-					for _, artifact := range toPush {
-						if err := pushArtifact(artifact.FilePath, cardID, token.WorkspaceID); err != nil {
-							fmt.Fprintf(os.Stderr, "warning: failed to push %s: %v\n", artifact.FilePath, err)
+					limit, err := artifactSizeLimit("", false)
+					if err != nil {
+						return err
+					}
+					var progress io.Writer = os.Stdout
+					if jsonOut {
+						progress = os.Stderr
+					}
+					_, errs := pushArtifacts(cmd.Context(), toPush, token.WorkspaceID, defaultPushConcurrency, limit, progress)
+
+					for i, artifact := range toPush {
+						if errors.Is(errs[i], context.Canceled) {
 							continue
 						}
-						pushedCount++
+						if errs[i] != nil {
+							logWarn("failed to push %s: %v", artifact.FilePath, errs[i])
+							result.ArtifactsFailed++
+							continue
+						}
+						result.ArtifactsPushed++
 					}
 
-					if pushedCount > 0 {
-						_ = saveStagedArtifacts(remaining)
-						fmt.Printf("Pushed %d artifact(s).\n", pushedCount)
+					if err := settleStagedArtifacts(toPush, errs); err != nil {
+						logWarn("failed to update staged artifacts: %v", err)
+					}
+					if result.ArtifactsPushed > 0 {
+						say("Pushed %d artifact(s).\n", result.ArtifactsPushed)
 					}
 				}
 			}
 
+			// Stop before changing the card if the push was interrupted.
+			if err := cmd.Context().Err(); err != nil {
+				return fmt.Errorf("interrupted before transitioning card %s", cardID)
+			}
+
 			// Step 2: Transition card to review.
-			fmt.Printf("Transitioning card %s to review...\n", cardID)
-			transitionBody := map[string]string{"target_lane": "review"}
-			_, err = apiClient.Post(api.CardTransitionPath(cardID), transitionBody)
-			if err != nil {
-				if isNetworkErr(err) {
-					if qErr := offQueue.Add("POST", api.CardTransitionPath(cardID), transitionBody); qErr == nil {
-						fmt.Println("Queued transition offline. Will flush on next connection.")
-					}
-				} else {
-					return fmt.Errorf("failed to transition card to review: %w", err)
+			say("Transitioning card %s to review...\n", cardID)
+			transitionBody := map[string]string{"target_lane": toDBLane("review")}
+			_, queued, err := apiPost(api.CardTransitionPath(cardID), transitionBody)
+			switch {
+			case err != nil:
+				result.Transition = "failed"
+				if isGovernanceRejection(err.Error()) {
+					result.Transition = "rejected"
+				}
+				if jsonOut {
+					printDoneSummary(result)
 				}
-			} else {
-				fmt.Printf("Card %s is now in review.\n", cardID)
+				return fmt.Errorf("failed to transition card to review: %w", err)
+			case queued:
+				result.Transition = "queued"
+				say("Queued transition offline. Will flush on next connection.\n")
+			default:
+				result.Transition = "success"
+				say("Card %s is now in review.\n", cardID)
 			}
 
-			// Step 3: Optionally emit a pattern signal with the summary.
-			if summary != "" {
-				summary = strings.TrimSpace(summary)
-				signalBody := map[string]interface{}{
-					"signals": []map[string]string{
-						{
-							"card_id":     cardID,
-							"signal_type": "pattern",
-							"content":     summary,
-						},
-					},
-				}
-				_, err = apiClient.Post(api.PathSignals, signalBody)
-				if err != nil {
-					if isNetworkErr(err) {
-						if qErr := offQueue.Add("POST", api.PathSignals, signalBody); qErr == nil {
-							fmt.Println("Queued signal offline. Will flush on next connection.")
-						}
-					} else {
-						fmt.Fprintf(os.Stderr, "warning: failed to emit signal: %v\n", err)
-					}
-				} else {
-					fmt.Println("Pattern signal emitted.")
+			// Step 3: Optionally emit a signal with the summary.
+			summary = strings.TrimSpace(summary)
+			if summary != "" && !noSignal {
+				queued, err := emitSignal(cardID, signalType, doneSignalContent(summary, result.ArtifactsPushed))
+				switch {
+				case err != nil:
+					logWarn("failed to emit signal: %v", err)
+				case queued:
+					result.SignalEmitted, result.SignalQueued = true, true
+					say("Queued signal offline. Will flush on next connection.\n")
+				default:
+					result.SignalEmitted = true
+					say("Signal emitted: [%s]\n", signalType)
 				}
 			}
 
+			if jsonOut {
+				printDoneSummary(result)
+				return nil
+			}
 			fmt.Printf("\nDone. Card %s submitted for review.\n", cardID)
+			if summary != "" && noSignal {
+				fmt.Printf("Summary: %s\n", summary)
+			}
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&summary, "summary", "", "Summary text to emit as a pattern signal")
+	cmd.Flags().StringVar(&summary, "summary", "", "Summary text to emit as a signal")
+	cmd.Flags().StringVar(&summaryFile, "summary-file", "", "Read the summary from a file (- for stdin)")
+	cmd.MarkFlagsMutuallyExclusive("summary", "summary-file")
+	cmd.Flags().StringVar(&signalType, "signal-type", "pattern",
+		"Type of the summary signal: "+strings.Join(validSignalTypes, ", "))
+	cmd.Flags().BoolVar(&noSignal, "no-signal", false, "Don't emit a signal, even with --summary")
+	cmd.MarkFlagsMutuallyExclusive("no-signal", "signal-type")
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print a JSON summary of what was done instead of progress text")
 	return cmd
 }
+
+// doneSignalContent appends the pushed artifact count to the summary, on its
+// own paragraph when the summary is a multi-line report.
+func doneSignalContent(summary string, pushed int) string {
+	sep := " "
+	if strings.Contains(summary, "\n") {
+		sep = "\n\n"
+	}
+	return fmt.Sprintf("%s%s(%d artifact(s) pushed)", summary, sep, pushed)
+}
+
+func printDoneSummary(s doneSummary) {
+	out, err := jsonFormatter{}.Format(s)
+	if err != nil {
+		logWarn("failed to format summary: %v", err)
+		return
+	}
+	fmt.Print(out)
+}
@@ -0,0 +1,55 @@
+package commands
+
+import (
+	"fmt"
+
+	"github.com/spf13/cobra"
+)
+
+// lifecycleWalkthrough is the curated example printed by `dea examples`.
+const lifecycleWalkthrough = `A typical card lifecycle
+
+1. Authenticate once per machine:
+
+     dea auth login
+     dea auth status
+
+2. Find work on the board and claim a card. Claiming makes it the current
+   card, so later commands can say "current" instead of the ID:
+
+     dea pull board --lane ready
+     dea claim card-123
+     dea pull context
+
+3. Work on the card. Stage files as you produce them and record what you
+   learn along the way:
+
+     dea artifact stage docs/design.md
+     dea artifact stage src/uploader.go
+     dea signal --card current --type discovery --content "Uploads retry on 5xx only"
+
+4. Move the card when its state changes:
+
+     dea transition current blocked --label needs-info
+     dea transition current in-progress
+
+5. Finish: push staged artifacts, move the card to review, and leave a
+   summary signal in one step:
+
+     dea done current --summary "Added retry to the uploader"
+
+If the endpoint is unreachable, writes are queued and replayed on the next
+connection. Run "dea <command> --help" for every flag and more examples.
+`
+
+func newExamplesCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "examples",
+		Short: "Show a walkthrough of a typical card lifecycle",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			fmt.Print(lifecycleWalkthrough)
+			return nil
+		},
+	}
+}
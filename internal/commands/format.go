@@ -0,0 +1,306 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/spf13/cobra"
+)
+
+// Formatter renders a command's result for output.
+type Formatter interface {
+	Format(v interface{}) (string, error)
+}
+
+// formatters maps --format names to constructors. The template argument is
+// the --template text and only matters for the template formatter. "table"
+// is not registered: it selects the command's own human-readable output.
+var formatters = map[string]func(tmpl string) (Formatter, error){
+	"json":     func(string) (Formatter, error) { return jsonFormatter{}, nil },
+	"yaml":     func(string) (Formatter, error) { return yamlFormatter{}, nil },
+	"csv":      func(string) (Formatter, error) { return csvFormatter{}, nil },
+	"template": newTemplateFormatter,
+}
+
+// outputOptions holds the --format, --template and --json flags of a command.
+type outputOptions struct {
+	format   string
+	template string
+	json     bool
+}
+
+// addOutputFlags registers the output selection flags on cmd.
+func addOutputFlags(cmd *cobra.Command, o *outputOptions) {
+	cmd.Flags().StringVar(&o.format, "format", "table", "Output format: table, json, yaml, csv, or template")
+	cmd.Flags().StringVar(&o.template, "template", "", "Go template to render (implies --format template)")
+	cmd.Flags().BoolVar(&o.json, "json", false, "Shorthand for --format json")
+}
+
+// formatter returns the selected Formatter, or nil for table output.
+func (o *outputOptions) formatter() (Formatter, error) {
+	name := o.format
+	switch {
+	case o.json:
+		name = "json"
+	case o.template != "":
+		name = "template"
+	}
+	if name == "" || name == "table" {
+		return nil, nil
+	}
+
+	newFormatter, ok := formatters[name]
+	if !ok {
+		names := make([]string, 0, len(formatters)+1)
+		names = append(names, "table")
+		for n := range formatters {
+			names = append(names, n)
+		}
+		sort.Strings(names[1:])
+		return nil, fmt.Errorf("unknown format %q. Valid formats: %s", name, strings.Join(names, ", "))
+	}
+	return newFormatter(o.template)
+}
+
+// render prints v with the selected formatter. It returns false when table
+// output was selected, in which case the caller prints its own output.
+func (o *outputOptions) render(v interface{}) (bool, error) {
+	f, err := o.formatter()
+	if err != nil || f == nil {
+		return false, err
+	}
+
+	out, err := f.Format(v)
+	if err != nil {
+		return true, err
+	}
+	fmt.Print(out)
+	return true, nil
+}
+
+// normalize round-trips v through JSON so formatters see the same field
+// names and shapes as --format json: maps, slices, strings, float64, bool, nil.
+func normalize(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var out interface{}
+	if err := json.Unmarshal(data, &out); err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+type jsonFormatter struct{}
+
+func (jsonFormatter) Format(v interface{}) (string, error) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return "", err
+	}
+	return string(data) + "\n", nil
+}
+
+type yamlFormatter struct{}
+
+func (yamlFormatter) Format(v interface{}) (string, error) {
+	n, err := normalize(v)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeYAML(&b, n, 0)
+	return b.String(), nil
+}
+
+// writeYAML emits block-style YAML for a normalized value. Map keys are
+// sorted so output is stable.
+func writeYAML(b *strings.Builder, v interface{}, indent int) {
+	pad := strings.Repeat("  ", indent)
+
+	switch t := v.(type) {
+	case map[string]interface{}:
+		if len(t) == 0 {
+			b.WriteString(pad + "{}\n")
+			return
+		}
+		keys := make([]string, 0, len(t))
+		for k := range t {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			if s, ok := yamlInline(t[k]); ok {
+				fmt.Fprintf(b, "%s%s: %s\n", pad, yamlString(k), s)
+				continue
+			}
+			fmt.Fprintf(b, "%s%s:\n", pad, yamlString(k))
+			writeYAML(b, t[k], indent+1)
+		}
+
+	case []interface{}:
+		if len(t) == 0 {
+			b.WriteString(pad + "[]\n")
+			return
+		}
+		for _, item := range t {
+			if s, ok := yamlInline(item); ok {
+				fmt.Fprintf(b, "%s- %s\n", pad, s)
+				continue
+			}
+			// Render one level deeper, then hang the first line off the "- ".
+			var nested strings.Builder
+			writeYAML(&nested, item, indent+1)
+			b.WriteString(pad + "- " + strings.TrimPrefix(nested.String(), pad+"  "))
+		}
+
+	default:
+		s, _ := yamlInline(v)
+		b.WriteString(pad + s + "\n")
+	}
+}
+
+// yamlInline renders scalars and empty collections on a single line.
+func yamlInline(v interface{}) (string, bool) {
+	switch t := v.(type) {
+	case nil:
+		return "null", true
+	case bool:
+		return strconv.FormatBool(t), true
+	case float64:
+		return strconv.FormatFloat(t, 'f', -1, 64), true
+	case string:
+		return yamlString(t), true
+	case map[string]interface{}:
+		return "{}", len(t) == 0
+	case []interface{}:
+		return "[]", len(t) == 0
+	}
+	return fmt.Sprintf("%v", v), true
+}
+
+// yamlString quotes s when it would otherwise be read back as something
+// other than the same plain string.
+func yamlString(s string) string {
+	needsQuote := s == "" ||
+		strings.ContainsAny(s, ":#{}[],&*!|>'\"%@`\n\t\\") ||
+		strings.TrimSpace(s) != s ||
+		strings.HasPrefix(s, "-") || strings.HasPrefix(s, "?")
+	switch strings.ToLower(s) {
+	case "true", "false", "yes", "no", "on", "off", "null", "~":
+		needsQuote = true
+	}
+	if _, err := strconv.ParseFloat(s, 64); err == nil {
+		needsQuote = true
+	}
+	if needsQuote {
+		return strconv.Quote(s)
+	}
+	return s
+}
+
+type csvFormatter struct{}
+
+// Format writes one row per object with a header of all keys, sorted. A
+// single object becomes a single row; nested values are written as JSON.
+func (csvFormatter) Format(v interface{}) (string, error) {
+	n, err := normalize(v)
+	if err != nil {
+		return "", err
+	}
+
+	var rows []map[string]interface{}
+	switch t := n.(type) {
+	case []interface{}:
+		for _, item := range t {
+			if obj, ok := item.(map[string]interface{}); ok {
+				rows = append(rows, obj)
+			} else {
+				rows = append(rows, map[string]interface{}{"value": item})
+			}
+		}
+	case map[string]interface{}:
+		rows = []map[string]interface{}{t}
+	default:
+		rows = []map[string]interface{}{{"value": t}}
+	}
+
+	seen := map[string]bool{}
+	var header []string
+	for _, row := range rows {
+		for k := range row {
+			if !seen[k] {
+				seen[k] = true
+				header = append(header, k)
+			}
+		}
+	}
+	sort.Strings(header)
+
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+	if err := w.Write(header); err != nil {
+		return "", err
+	}
+	for _, row := range rows {
+		record := make([]string, len(header))
+		for i, k := range header {
+			record[i] = csvCell(row[k])
+		}
+		if err := w.Write(record); err != nil {
+			return "", err
+		}
+	}
+	w.Flush()
+	return buf.String(), w.Error()
+}
+
+func csvCell(v interface{}) string {
+	switch t := v.(type) {
+	case nil:
+		return ""
+	case string:
+		return t
+	case map[string]interface{}, []interface{}:
+		data, _ := json.Marshal(t)
+		return string(data)
+	}
+	s, _ := yamlInline(v)
+	return s
+}
+
+// templateFormatter executes a Go text/template against the normalized
+// value, so fields are addressed by their JSON names: {{range .}}{{.id}}{{end}}.
+type templateFormatter struct {
+	tmpl *template.Template
+}
+
+func newTemplateFormatter(text string) (Formatter, error) {
+	if text == "" {
+		return nil, fmt.Errorf("--format template requires --template")
+	}
+	tmpl, err := template.New("output").Parse(text)
+	if err != nil {
+		return nil, fmt.Errorf("invalid --template: %w", err)
+	}
+	return templateFormatter{tmpl: tmpl}, nil
+}
+
+func (f templateFormatter) Format(v interface{}) (string, error) {
+	n, err := normalize(v)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	if err := f.tmpl.Execute(&b, n); err != nil {
+		return "", err
+	}
+	return b.String(), nil
+}
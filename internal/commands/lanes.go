@@ -0,0 +1,79 @@
+package commands
+
+import (
+	"sort"
+	"strings"
+)
+
+// defaultLaneAliases maps lane names used on the command line to the names
+// stored in the database. lane_aliases in config adds to and overrides it.
+// Names found in neither are converted by swapping "-" for "_".
+var defaultLaneAliases = map[string]string{
+	"in-progress": "in_progress",
+}
+
+// laneAliases returns the effective CLI-to-DB lane table.
+func laneAliases() map[string]string {
+	aliases := make(map[string]string, len(defaultLaneAliases))
+	for k, v := range defaultLaneAliases {
+		aliases[k] = v
+	}
+	if cfg != nil {
+		for k, v := range cfg.LaneAliases {
+			aliases[strings.ToLower(k)] = v
+		}
+	}
+	return aliases
+}
+
+// toDBLane converts a lane name as typed by the user to the database name.
+func toDBLane(name string) string {
+	name = strings.ToLower(strings.TrimSpace(name))
+	if db, ok := laneAliases()[name]; ok {
+		return db
+	}
+	return strings.ReplaceAll(name, "-", "_")
+}
+
+// toDisplayLane converts a database lane name to the name the CLI shows and
+// accepts. When several aliases map to the lane, a name from validStages is
+// preferred, then the alphabetically first, so output is stable.
+func toDisplayLane(db string) string {
+	var names []string
+	for cli, target := range laneAliases() {
+		if strings.EqualFold(target, db) {
+			names = append(names, cli)
+		}
+	}
+	if len(names) == 0 {
+		return strings.ReplaceAll(strings.ToLower(db), "_", "-")
+	}
+
+	sort.Strings(names)
+	for _, n := range names {
+		if containsString(validStages, n) {
+			return n
+		}
+	}
+	return names[0]
+}
+
+// isKnownStage reports whether name, in either CLI or DB spelling, is one of
+// validStages or a lane_aliases name.
+func isKnownStage(name string) bool {
+	if _, ok := laneAliases()[strings.ToLower(strings.TrimSpace(name))]; ok {
+		return true
+	}
+	for _, s := range validStages {
+		if sameLane(s, name) {
+			return true
+		}
+	}
+	return false
+}
+
+// sameLane reports whether two lane names, in either CLI or DB spelling,
+// refer to the same lane.
+func sameLane(a, b string) bool {
+	return strings.EqualFold(toDBLane(a), toDBLane(b))
+}
@@ -0,0 +1,139 @@
+package commands
+
+import (
+	"testing"
+
+	"github.com/dea-exmachina/dea-cli/internal/config"
+)
+
+// withLaneAliases sets cfg to a config holding aliases for the test.
+func withLaneAliases(t *testing.T, aliases map[string]string) {
+	t.Helper()
+	old := cfg
+	cfg = &config.Config{LaneAliases: aliases}
+	t.Cleanup(func() { cfg = old })
+}
+
+func TestLaneRoundTrip(t *testing.T) {
+	withLaneAliases(t, nil)
+
+	for _, tc := range []struct {
+		cli string
+		db  string
+	}{
+		{"backlog", "backlog"},
+		{"in-progress", "in_progress"},
+		{"code-review", "code_review"},
+	} {
+		if got := toDBLane(tc.cli); got != tc.db {
+			t.Errorf("toDBLane(%q) = %q, want %q", tc.cli, got, tc.db)
+		}
+		if got := toDisplayLane(tc.db); got != tc.cli {
+			t.Errorf("toDisplayLane(%q) = %q, want %q", tc.db, got, tc.cli)
+		}
+	}
+
+	for _, stage := range validStages {
+		if got := toDisplayLane(toDBLane(stage)); got != stage {
+			t.Errorf("stage %q round-trips to %q", stage, got)
+		}
+	}
+}
+
+func TestToDBLaneNormalizesInput(t *testing.T) {
+	withLaneAliases(t, nil)
+
+	for _, in := range []string{"In-Progress", " in-progress ", "IN_PROGRESS"} {
+		if got := toDBLane(in); got != "in_progress" {
+			t.Errorf("toDBLane(%q) = %q, want in_progress", in, got)
+		}
+	}
+	if got := toDisplayLane("IN_PROGRESS"); got != "in-progress" {
+		t.Errorf("toDisplayLane(IN_PROGRESS) = %q, want in-progress", got)
+	}
+}
+
+func TestLaneAliasesFromConfig(t *testing.T) {
+	withLaneAliases(t, map[string]string{
+		"wip":         "in_progress",
+		"QA":          "qa_review",
+		"in-progress": "doing",
+	})
+
+	for _, tc := range []struct {
+		cli string
+		db  string
+	}{
+		{"wip", "in_progress"},
+		{"qa", "qa_review"},
+		{"QA", "qa_review"},
+		{"in-progress", "doing"},
+	} {
+		if got := toDBLane(tc.cli); got != tc.db {
+			t.Errorf("toDBLane(%q) = %q, want %q", tc.cli, got, tc.db)
+		}
+	}
+
+	for _, tc := range []struct {
+		db  string
+		cli string
+	}{
+		// Config moved in-progress to "doing", leaving wip as the only
+		// alias for in_progress.
+		{"in_progress", "wip"},
+		{"qa_review", "qa"},
+		{"doing", "in-progress"},
+	} {
+		if got := toDisplayLane(tc.db); got != tc.cli {
+			t.Errorf("toDisplayLane(%q) = %q, want %q", tc.db, got, tc.cli)
+		}
+		if got := toDBLane(toDisplayLane(tc.db)); got != tc.db {
+			t.Errorf("%q round-trips to %q", tc.db, got)
+		}
+	}
+}
+
+func TestToDisplayLanePrefersValidStage(t *testing.T) {
+	withLaneAliases(t, map[string]string{
+		"active": "in_progress",
+		"wip":    "in_progress",
+	})
+
+	if got := toDisplayLane("in_progress"); got != "in-progress" {
+		t.Errorf("toDisplayLane(in_progress) = %q, want in-progress", got)
+	}
+
+	withLaneAliases(t, map[string]string{
+		"wip":    "doing",
+		"active": "doing",
+	})
+	if got := toDisplayLane("doing"); got != "active" {
+		t.Errorf("toDisplayLane(doing) = %q, want active (alphabetically first)", got)
+	}
+}
+
+func TestSameLane(t *testing.T) {
+	withLaneAliases(t, map[string]string{"wip": "in_progress"})
+
+	if !sameLane("wip", "in_progress") || !sameLane("In-Progress", "wip") {
+		t.Error("aliases of in_progress should be the same lane")
+	}
+	if sameLane("review", "in-progress") {
+		t.Error("review and in-progress should differ")
+	}
+}
+
+func TestIsKnownStage(t *testing.T) {
+	withLaneAliases(t, map[string]string{"QA": "qa_review"})
+
+	for _, name := range []string{"review", "In-Progress", "in_progress", "blocked", "qa", "QA"} {
+		if !isKnownStage(name) {
+			t.Errorf("isKnownStage(%q) = false, want true", name)
+		}
+	}
+	for _, name := range []string{"card-123", "qa_review", "", "reviewed"} {
+		if isKnownStage(name) {
+			t.Errorf("isKnownStage(%q) = true, want false", name)
+		}
+	}
+}
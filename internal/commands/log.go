@@ -0,0 +1,87 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	logFormatText = "text"
+	logFormatJSON = "json"
+)
+
+// logCommand is the running command's path without the "dea" prefix, e.g.
+// "artifact push". It is set before the command runs.
+var logCommand string
+
+// logRecord is one line of --log-format json output.
+type logRecord struct {
+	Time    string `json:"time"`
+	Level   string `json:"level"`
+	Msg     string `json:"msg"`
+	Command string `json:"command,omitempty"`
+}
+
+// logInfo reports progress or other informational messages on stderr.
+func logInfo(format string, args ...interface{}) {
+	logMessage("info", format, args...)
+}
+
+// logWarn reports a problem the command recovered from.
+func logWarn(format string, args ...interface{}) {
+	logMessage("warn", format, args...)
+}
+
+// logError reports the error a command failed with.
+func logError(format string, args ...interface{}) {
+	logMessage("error", format, args...)
+}
+
+// logMessage writes a message to stderr, either as text or, with
+// --log-format json, as a single-line JSON object so machine consumers can
+// parse stderr while reading the command's data from stdout.
+func logMessage(level, format string, args ...interface{}) {
+	msg := fmt.Sprintf(format, args...)
+	if logFormatFlag != logFormatJSON {
+		if level == "warn" {
+			msg = "warning: " + msg
+		}
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+
+	data, err := json.Marshal(logRecord{
+		Time:    time.Now().UTC().Format(time.RFC3339),
+		Level:   level,
+		Msg:     msg,
+		Command: logCommand,
+	})
+	if err != nil {
+		fmt.Fprintln(os.Stderr, msg)
+		return
+	}
+	os.Stderr.Write(append(data, '\n'))
+}
+
+// logWriter is an io.Writer that logs each line written to it at info
+// level, for output produced by code that takes a writer.
+type logWriter struct {
+	buf bytes.Buffer
+}
+
+func (w *logWriter) Write(p []byte) (int, error) {
+	w.buf.Write(p)
+	for {
+		line, err := w.buf.ReadString('\n')
+		if err != nil {
+			// Keep the partial line until the rest arrives.
+			w.buf.Reset()
+			w.buf.WriteString(line)
+			return len(p), nil
+		}
+		logInfo("%s", line[:len(line)-1])
+	}
+}
@@ -0,0 +1,58 @@
+package commands
+
+import (
+	"errors"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+// offlineRetryDelay is the pause before re-checking connectivity after a
+// failed connection, so a momentary blip is retried rather than queued.
+const offlineRetryDelay = 500 * time.Millisecond
+
+// apiPost POSTs body to path. If the connection could not be made it retries
+// once after a short pause; only if that also fails with a network error is
+// the request queued for the next flush, in which case queued is true and
+// err is nil. Network errors after the request may have reached the server
+// (a dropped connection, a timeout) are queued without the immediate retry,
+// so a request that did go through is not sent twice on the spot. With
+// --no-queue the network error is returned instead of queueing.
+func apiPost(path string, body interface{}) (data []byte, queued bool, err error) {
+	return sendOrQueue("POST", path, body, func() ([]byte, error) {
+		return apiClient.Post(path, body)
+	})
+}
+
+// apiDelete DELETEs path, retrying and queueing like apiPost.
+func apiDelete(path string) (queued bool, err error) {
+	_, queued, err = sendOrQueue("DELETE", path, nil, func() ([]byte, error) {
+		return apiClient.Delete(path)
+	})
+	return queued, err
+}
+
+// sendOrQueue calls send, retrying once and then queueing the request as
+// described on apiPost.
+func sendOrQueue(method, path string, body interface{}, send func() ([]byte, error)) (data []byte, queued bool, err error) {
+	data, err = send()
+	if err == nil || !api.IsNetworkError(err) {
+		return data, false, err
+	}
+
+	if errors.Is(err, api.ErrNotSent) {
+		time.Sleep(offlineRetryDelay)
+		data, err = send()
+		if err == nil || !api.IsNetworkError(err) {
+			return data, false, err
+		}
+	}
+	if noQueueFlag {
+		return nil, false, err
+	}
+
+	if qErr := offQueue.Add(method, path, body); qErr != nil {
+		return nil, false, err
+	}
+	return nil, true, nil
+}
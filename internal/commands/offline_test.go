@@ -0,0 +1,102 @@
+package commands
+
+import (
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/queue"
+)
+
+// withOfflineQueue points offQueue at an empty queue under a temp DEA_HOME
+// and apiClient at endpoint.
+func withOfflineQueue(t *testing.T, endpoint string) {
+	t.Helper()
+	t.Setenv("DEA_HOME", t.TempDir())
+	oldQueue, oldClient := offQueue, apiClient
+	offQueue = queue.New()
+	apiClient = api.NewClient(endpoint, 5, staticToken("tok"))
+	t.Cleanup(func() { offQueue, apiClient = oldQueue, oldClient })
+}
+
+func queued(t *testing.T) int {
+	t.Helper()
+	items, err := offQueue.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	return len(items)
+}
+
+func TestSendOrQueueRetriesConnectFailure(t *testing.T) {
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	endpoint := "http://" + l.Addr().String()
+	l.Close()
+	withOfflineQueue(t, endpoint)
+
+	var attempts int
+	_, wasQueued, err := sendOrQueue("POST", api.PathSignals, map[string]string{}, func() ([]byte, error) {
+		attempts++
+		return apiClient.Post(api.PathSignals, map[string]string{})
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 2 {
+		t.Errorf("sent %d times, want a retry after the failed connection", attempts)
+	}
+	if !wasQueued || queued(t) != 1 {
+		t.Errorf("queued = %v with %d items, want the request queued", wasQueued, queued(t))
+	}
+}
+
+func TestSendOrQueueDoesNotResendAfterDroppedConnection(t *testing.T) {
+	var received atomic.Int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		received.Add(1)
+		conn, _, err := w.(http.Hijacker).Hijack()
+		if err != nil {
+			t.Error(err)
+			return
+		}
+		conn.Close()
+	}))
+	defer srv.Close()
+	withOfflineQueue(t, srv.URL)
+
+	_, wasQueued, err := apiPost(api.PathSignals, map[string]string{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if n := received.Load(); n != 1 {
+		t.Errorf("server received the request %d times, want once", n)
+	}
+	if !wasQueued || queued(t) != 1 {
+		t.Errorf("queued = %v with %d items, want the request queued", wasQueued, queued(t))
+	}
+}
+
+func TestSendOrQueueNoQueue(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		conn, _, _ := w.(http.Hijacker).Hijack()
+		conn.Close()
+	}))
+	defer srv.Close()
+	withOfflineQueue(t, srv.URL)
+	noQueueFlag = true
+	defer func() { noQueueFlag = false }()
+
+	_, wasQueued, err := apiPost(api.PathSignals, map[string]string{})
+	if !api.IsNetworkError(err) {
+		t.Errorf("err = %v, want the network error", err)
+	}
+	if wasQueued || queued(t) != 0 {
+		t.Error("request queued despite --no-queue")
+	}
+}
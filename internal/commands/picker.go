@@ -0,0 +1,93 @@
+package commands
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// cardIDArg returns the card ID from args, expanding "current", or prompts
+// for one with pickCard when it was omitted.
+func cardIDArg(args []string, keep func(map[string]interface{}) bool) (string, error) {
+	if len(args) > 0 {
+		return resolveCardID(args[0])
+	}
+	return pickCard(keep)
+}
+
+// pickCard stands in for an omitted card ID: it lists the board's cards
+// (those keep accepts, when non-nil) and asks the user to choose one by
+// number. Without a terminal on stdin it fails so scripts never block.
+func pickCard(keep func(map[string]interface{}) bool) (string, error) {
+	if !isTerminal(os.Stdin) {
+		return "", fmt.Errorf("card ID is required when not running interactively")
+	}
+
+	projectID, err := resolveProject("")
+	if err != nil {
+		return "", err
+	}
+	cards, err := fetchBoard(projectID)
+	if err != nil {
+		return "", handleAPIError(err, "board", projectID, "list")
+	}
+	if keep != nil {
+		cards = filterCards(cards, keep)
+	}
+	if len(cards) == 0 {
+		return "", fmt.Errorf("no cards to choose from in project %s", projectID)
+	}
+
+	for i, card := range cards {
+		title := strField(card, "title", "(no title)")
+		if len(title) > 30 {
+			title = title[:27] + "..."
+		}
+		fmt.Printf("%3d) %-20s  %-30s  %s\n", i+1,
+			strField(card, "id", strField(card, "card_id", "?")), title,
+			strField(card, "lane", strField(card, "status", "?")))
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for {
+		fmt.Printf("Select a card [1-%d]: ", len(cards))
+		if !scanner.Scan() {
+			return "", fmt.Errorf("no card selected")
+		}
+		input := strings.TrimSpace(scanner.Text())
+		if input == "" {
+			continue
+		}
+		n, err := strconv.Atoi(input)
+		if err != nil || n < 1 || n > len(cards) {
+			fmt.Printf("Enter a number between 1 and %d.\n", len(cards))
+			continue
+		}
+		id := strField(cards[n-1], "id", strField(cards[n-1], "card_id", ""))
+		if id == "" {
+			return "", fmt.Errorf("selected card has no ID")
+		}
+		return id, nil
+	}
+}
+
+// confirm asks a yes/no question on stdin and reports whether the answer was
+// yes. Without a terminal on stdin it fails, so scripts must pass --yes
+// rather than block or silently proceed.
+func confirm(prompt string) (bool, error) {
+	if !isTerminal(os.Stdin) {
+		return false, fmt.Errorf("confirmation required; pass --yes when not running interactively")
+	}
+	fmt.Printf("%s [y/N]: ", prompt)
+	scanner := bufio.NewScanner(os.Stdin)
+	if !scanner.Scan() {
+		return false, nil
+	}
+	switch strings.ToLower(strings.TrimSpace(scanner.Text())) {
+	case "y", "yes":
+		return true, nil
+	}
+	return false, nil
+}
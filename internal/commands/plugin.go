@@ -0,0 +1,60 @@
+package commands
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/spf13/cobra"
+)
+
+// pluginPrefix is prepended to an unknown subcommand name to find a plugin
+// executable on PATH, so `dea foo` runs `dea-foo`.
+const pluginPrefix = "dea-"
+
+// runPlugin runs the external `dea-<name>` executable for an unknown
+// subcommand, the way git and kubectl do. Built-in commands always take
+// precedence. The plugin gets the remaining args, the terminal, and the
+// resolved endpoint and token in DEA_ENDPOINT and DEA_TOKEN. Returns
+// handled=false when args do not name a plugin.
+func runPlugin(ctx context.Context, root *cobra.Command, args []string) (handled bool, err error) {
+	if len(args) == 0 || strings.HasPrefix(args[0], "-") {
+		return false, nil
+	}
+
+	// help and completion are only added once Execute runs.
+	root.InitDefaultHelpCmd()
+	root.InitDefaultCompletionCmd()
+	if cmd, _, err := root.Find(args[:1]); err == nil && cmd != root {
+		return false, nil
+	}
+
+	path, err := exec.LookPath(pluginPrefix + args[0])
+	if err != nil {
+		return false, nil
+	}
+
+	if err := initGlobals(ctx); err != nil {
+		return true, err
+	}
+	env := append(os.Environ(), "DEA_ENDPOINT="+cfg.Endpoint)
+	if token := tokenStore.Load(); token != nil {
+		env = append(env, "DEA_TOKEN="+token.WorkspaceToken)
+	}
+
+	plugin := exec.Command(path, args[1:]...)
+	plugin.Env = env
+	plugin.Stdin = os.Stdin
+	plugin.Stdout = os.Stdout
+	plugin.Stderr = os.Stderr
+
+	err = plugin.Run()
+	var exitErr *exec.ExitError
+	if errors.As(err, &exitErr) {
+		// The plugin reported its own error; just pass its status on.
+		return true, &exitError{code: exitErr.ExitCode()}
+	}
+	return true, err
+}
@@ -1,11 +1,19 @@
 package commands
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
 	"os"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/poll"
 	"github.com/spf13/cobra"
 )
 
@@ -23,97 +31,391 @@ func newPullCommand() *cobra.Command {
 }
 
 func newPullCardCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "card <card-id>",
+	var (
+		watch    bool
+		interval time.Duration
+		fields   []string
+		open     bool
+		out      outputOptions
+	)
+
+	cmd := &cobra.Command{
+		Use:   "card [card-id]",
 		Short: "Pull context for a specific card",
-		Args:  cobra.ExactArgs(1),
+		Long: "Pull context for a specific card.\n\n" +
+			"When the card ID is omitted on a terminal, pick the card from the board.",
+		Example: `  # Show a card and open it in the web dashboard
+  dea pull card card-123 --open`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cardID := args[0]
 			mustLoadToken()
 
-			data, err := apiClient.Get(api.CardContextPath(cardID))
+			cardID, err := cardIDArg(args, nil)
 			if err != nil {
-				return handleAPIError(err, "card", cardID, "context")
+				return err
 			}
 
-			// Write to .dea-context/card-<id>.json in the current directory.
-			if err := os.MkdirAll(".dea-context", 0755); err != nil {
-				return fmt.Errorf("failed to create .dea-context directory: %w", err)
+			card, err := pullCardContext(cardID)
+			var fetched time.Time
+			if errors.Is(err, api.ErrNetwork) {
+				// Offline: fall back to what the last successful pull saved.
+				if cached, at, cacheErr := cachedCardContext(cardID); cacheErr == nil {
+					card, fetched, err = cached, at, nil
+				}
 			}
-
-			outPath := fmt.Sprintf(".dea-context/card-%s.json", cardID)
-			if err := os.WriteFile(outPath, data, 0644); err != nil {
-				return fmt.Errorf("failed to write context file: %w", err)
+			if err != nil {
+				return err
 			}
-
-			// Parse and print summary — handle { data: { card: {...} } } wrapper.
-			var parsed map[string]interface{}
-			if err := json.Unmarshal(data, &parsed); err == nil {
-				card := parsed
-				// Unwrap { data: ... }
-				if d, ok := parsed["data"].(map[string]interface{}); ok {
-					card = d
+			if open {
+				if err := openCardInDashboard(cardID); err != nil {
+					return err
 				}
-				// Unwrap { card: ... } if present
-				if c, ok := card["card"].(map[string]interface{}); ok {
-					printCardSummary(c)
-				} else {
-					printCardSummary(card)
+			}
+
+			f, err := out.formatter()
+			if err != nil {
+				return err
+			}
+			if f != nil && watch {
+				return fmt.Errorf("--watch only works with table output")
+			}
+			if !fetched.IsZero() && (f != nil || len(fields) > 0) {
+				logWarn("offline: showing cached card fetched %s ago", formatAge(time.Since(fetched)))
+			}
+			if len(fields) > 0 {
+				card = projectFields(card, fields)
+				if f == nil {
+					printCardFields(card, fields)
+					return nil
 				}
-			} else {
-				fmt.Printf("Context written to %s\n", outPath)
+			}
+			if ok, err := out.render(card); ok || err != nil {
+				return err
 			}
 
-			return nil
+			printCardSummary(card)
+			printLinkedCards(cardID)
+			if !fetched.IsZero() {
+				fmt.Printf("(offline cache, fetched %s ago)\n", formatAge(time.Since(fetched)))
+			}
+
+			if !watch {
+				return nil
+			}
+			if interval <= 0 {
+				return fmt.Errorf("--interval must be positive")
+			}
+			return watchCard(cmd.Context(), cardID, card, interval)
 		},
 	}
+
+	cmd.Flags().BoolVar(&watch, "watch", false, "Keep polling and report lane, priority, or assignee changes")
+	cmd.Flags().DurationVar(&interval, "interval", 30*time.Second, "Polling interval for --watch")
+	cmd.Flags().StringSliceVar(&fields, "fields", nil, "Only output these card fields, e.g. id,title,lane")
+	cmd.MarkFlagsMutuallyExclusive("fields", "watch")
+	cmd.Flags().BoolVar(&open, "open", false, "Also open the card in the web dashboard (needs dashboard_url in config)")
+	addOutputFlags(cmd, &out)
+	return cmd
+}
+
+// projectFields returns a copy of card with only the named keys. Keys the
+// card doesn't have are included as null so every requested field appears.
+// Field names are trimmed in place.
+func projectFields(card map[string]interface{}, fields []string) map[string]interface{} {
+	projected := make(map[string]interface{}, len(fields))
+	for i, f := range fields {
+		f = strings.TrimSpace(f)
+		fields[i] = f
+		projected[f] = card[f]
+	}
+	return projected
+}
+
+// printCardFields prints one "field: value" line per requested field, in the
+// order given.
+func printCardFields(card map[string]interface{}, fields []string) {
+	for _, f := range fields {
+		fmt.Printf("%s: %s\n", f, columnValue(card, f))
+	}
+}
+
+// pullCardContext fetches a card's context, validates it, and writes it to
+// card-<id>.json in the context directory. Returns the parsed card.
+func pullCardContext(cardID string) (map[string]interface{}, error) {
+	card, _, _, err := pullCardContextIfChanged(cardID, api.Validators{})
+	return card, err
+}
+
+// pullCardContextIfChanged is pullCardContext for polling. It sends the
+// validators of the previous fetch and, when the server answers 304, returns
+// changed=false without re-reading or re-writing anything. The returned
+// validators are for the next poll.
+func pullCardContextIfChanged(cardID string, prev api.Validators) (card map[string]interface{}, next api.Validators, changed bool, err error) {
+	resp, changed, err := apiClient.GetIfChanged(api.CardContextPath(cardID), prev)
+	if err != nil {
+		return nil, prev, false, handleAPIError(err, "card", cardID, "context")
+	}
+	if !changed {
+		return nil, prev, false, nil
+	}
+	data := resp.Body
+
+	// Validate before persisting so an error-shaped 200 never ends up
+	// cached as if it were card context.
+	card, err = extractCard(data)
+	if err != nil {
+		return nil, prev, false, fmt.Errorf("invalid context for card %s: %w", cardID, err)
+	}
+
+	// Write to card-<id>.json in the context directory.
+	if err := ensureContextDir(); err != nil {
+		return nil, prev, false, err
+	}
+
+	if err := os.WriteFile(cardContextFile(cardID), data, 0644); err != nil {
+		return nil, prev, false, fmt.Errorf("failed to write context file: %w", err)
+	}
+
+	return card, api.ValidatorsFrom(resp), true, nil
+}
+
+// cardContextFile is where pullCardContext saves a card's context.
+func cardContextFile(cardID string) string {
+	return contextPath(fmt.Sprintf("card-%s.json", cardID))
+}
+
+// cachedCardContext returns the card saved by the last successful pull and
+// when it was fetched.
+func cachedCardContext(cardID string) (map[string]interface{}, time.Time, error) {
+	path := cardContextFile(cardID)
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	card, err := extractCard(data)
+	if err != nil {
+		return nil, time.Time{}, err
+	}
+	return card, info.ModTime(), nil
+}
+
+// formatAge renders a duration coarsely, e.g. "45s", "12m", "3h", "2d".
+func formatAge(d time.Duration) string {
+	switch {
+	case d < time.Minute:
+		return fmt.Sprintf("%ds", int(d.Seconds()))
+	case d < time.Hour:
+		return fmt.Sprintf("%dm", int(d.Minutes()))
+	case d < 24*time.Hour:
+		return fmt.Sprintf("%dh", int(d.Hours()))
+	}
+	return fmt.Sprintf("%dd", int(d.Hours()/24))
+}
+
+// parseAge parses a duration for age filters. On top of time.ParseDuration
+// units it accepts whole or fractional days and weeks, e.g. "3d" or "1.5w".
+func parseAge(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	for suffix, unit := range map[string]time.Duration{"d": 24 * time.Hour, "w": 7 * 24 * time.Hour} {
+		if num, ok := strings.CutSuffix(s, suffix); ok {
+			n, err := strconv.ParseFloat(num, 64)
+			if err != nil || n < 0 {
+				return 0, fmt.Errorf("invalid duration %q (use e.g. 3d, 12h, 2w)", s)
+			}
+			return time.Duration(n * float64(unit)), nil
+		}
+	}
+	d, err := time.ParseDuration(s)
+	if err != nil || d < 0 {
+		return 0, fmt.Errorf("invalid duration %q (use e.g. 3d, 12h, 2w)", s)
+	}
+	return d, nil
+}
+
+// watchedCardFields are the card fields --watch reports changes for.
+var watchedCardFields = []string{"lane", "priority", "assignee"}
+
+// watchCard polls a card until ctx is cancelled (Ctrl-C), printing one line
+// per changed field rather than the whole card. Polls are conditional, so an
+// unchanged card costs the server a 304 rather than the full context.
+func watchCard(ctx context.Context, cardID string, card map[string]interface{}, interval time.Duration) error {
+	fmt.Printf("Watching card %s every %s (Ctrl-C to stop)...\n", cardID, interval)
+
+	prev := watchedValues(card)
+	var validators api.Validators
+
+	err := poll.Until(ctx, poll.Options{Interval: interval}, func(context.Context) (bool, error) {
+		card, next, changed, err := pullCardContextIfChanged(cardID, validators)
+		if err != nil {
+			logWarn("%v", err)
+			return false, nil
+		}
+		validators = next
+		if !changed {
+			return false, nil
+		}
+
+		cur := watchedValues(card)
+		for _, field := range watchedCardFields {
+			if prev[field] != cur[field] {
+				fmt.Printf("%s  %s: %s -> %s\n", time.Now().Format("15:04:05"), field, prev[field], cur[field])
+			}
+		}
+		prev = cur
+		return false, nil
+	})
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+func watchedValues(card map[string]interface{}) map[string]string {
+	return map[string]string{
+		"lane":     strField(card, "lane", strField(card, "status", "")),
+		"priority": strField(card, "priority", ""),
+		"assignee": cardAssignee(card),
+	}
+}
+
+// extractCard parses a card context response, unwrapping { data: ... } and
+// { card: ... }, and checks that the result has the fields of a card.
+// Responses carrying an "error" field are returned as errors.
+func extractCard(data []byte) (map[string]interface{}, error) {
+	var parsed map[string]interface{}
+	if err := json.Unmarshal(data, &parsed); err != nil {
+		return nil, fmt.Errorf("response is not a JSON object: %w", err)
+	}
+
+	if msg := serverErrorMessage(parsed); msg != "" {
+		return nil, fmt.Errorf("server returned an error: %s", msg)
+	}
+
+	card, err := unwrapObject(data, "card")
+	if err != nil {
+		return nil, err
+	}
+
+	var missing []string
+	if strField(card, "id", strField(card, "card_id", "")) == "" {
+		missing = append(missing, "id")
+	}
+	if _, ok := card["title"]; !ok {
+		missing = append(missing, "title")
+	}
+	if strField(card, "lane", strField(card, "status", "")) == "" {
+		missing = append(missing, "lane")
+	}
+	if len(missing) > 0 {
+		return nil, fmt.Errorf("response is missing card field(s): %s", strings.Join(missing, ", "))
+	}
+	return card, nil
+}
+
+// serverErrorMessage returns the message of an { error: ... } body, which
+// may be a string or an object with a "message" field.
+func serverErrorMessage(body map[string]interface{}) string {
+	switch e := body["error"].(type) {
+	case string:
+		return e
+	case map[string]interface{}:
+		return strField(e, "message", "unknown error")
+	}
+	return ""
 }
 
 func newPullBoardCommand() *cobra.Command {
-	var projectSlug string
+	var (
+		projectSlug string
+		lane        string
+		mine        bool
+		assignee    string
+		stale       string
+		groupBy     string
+		columns     []string
+		count       bool
+		out         outputOptions
+	)
 
 	cmd := &cobra.Command{
 		Use:   "board",
 		Short: "List active cards on the board",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			mustLoadToken()
+			token := mustLoadToken()
+
+			if groupBy != "" && groupBy != "lane" && groupBy != "priority" {
+				return fmt.Errorf("invalid --group-by %q (use lane or priority)", groupBy)
+			}
+			var staleAfter time.Duration
+			if stale != "" {
+				d, err := parseAge(stale)
+				if err != nil {
+					return fmt.Errorf("invalid --stale: %w", err)
+				}
+				staleAfter = d
+			}
 
-			projectID := projectSlug
-			if projectID == "" {
-				projectID = cfg.DefaultProject
+			projectID, err := resolveProject(projectSlug)
+			if err != nil {
+				return err
 			}
-			if projectID == "" {
-				return fmt.Errorf("project ID required. Use --project <slug> or set default_project in config")
+
+			var me string
+			if mine {
+				if me, err = currentAgentID(token); err != nil {
+					return err
+				}
+				// An empty ID would match every unassigned card.
+				if me == "" {
+					return fmt.Errorf("cannot determine your agent ID (use --agent-id)")
+				}
 			}
 
-			path := api.PathCards + "?project_id=" + projectID
-			data, err := apiClient.Get(path)
+			cards, err := fetchBoard(projectID)
 			if err != nil {
+				if api.StatusCode(err) == http.StatusNotFound {
+					return fmt.Errorf("project %q not found", projectID)
+				}
 				return handleAPIError(err, "board", projectID, "list")
 			}
 
-			var cards []map[string]interface{}
-			if err := json.Unmarshal(data, &cards); err != nil {
-				// Try { data: [...] } wrapper or { data: { cards: [...] } }.
-				var resp map[string]interface{}
-				if err2 := json.Unmarshal(data, &resp); err2 == nil {
-					// { data: [...] }
-					if arr, ok := resp["data"].([]interface{}); ok {
-						for _, item := range arr {
-							if card, ok := item.(map[string]interface{}); ok {
-								cards = append(cards, card)
-							}
-						}
-					} else if d, ok := resp["data"].(map[string]interface{}); ok {
-						if arr, ok := d["cards"].([]interface{}); ok {
-							for _, item := range arr {
-								if card, ok := item.(map[string]interface{}); ok {
-									cards = append(cards, card)
-								}
-							}
-						}
-					}
+			if lane != "" {
+				cards = filterCards(cards, func(c map[string]interface{}) bool {
+					return cardInLane(c, lane)
+				})
+			}
+			if mine {
+				cards = filterCards(cards, func(c map[string]interface{}) bool {
+					return cardAssignee(c) == me
+				})
+			}
+			if assignee != "" {
+				cards = filterCards(cards, func(c map[string]interface{}) bool {
+					return cardAssignee(c) == assignee
+				})
+			}
+			if staleAfter > 0 {
+				cutoff := time.Now().Add(-staleAfter)
+				cards = filterCards(cards, func(c map[string]interface{}) bool {
+					updated, err := time.Parse(time.RFC3339, strField(c, "updated_at", ""))
+					return err == nil && updated.Before(cutoff)
+				})
+			}
+
+			if count {
+				if ok, err := out.render(map[string]int{"count": len(cards)}); ok || err != nil {
+					return err
 				}
+				fmt.Println(len(cards))
+				return nil
+			}
+
+			if ok, err := out.render(cards); ok || err != nil {
+				return err
 			}
 
 			if len(cards) == 0 {
@@ -121,36 +423,224 @@ func newPullBoardCommand() *cobra.Command {
 				return nil
 			}
 
+			if groupBy != "" {
+				printCardGroups(cards, groupBy)
+				return nil
+			}
+			if len(columns) > 0 {
+				printCardColumns(cards, columns)
+				return nil
+			}
 			printCardTable(cards)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&projectSlug, "project", "", "Project slug or ID")
+	addOutputFlags(cmd, &out)
+
+	cmd.Flags().StringVar(&projectSlug, "project", "", "Project slug or ID (default: $DEA_PROJECT, then default_project in config)")
+	cmd.Flags().StringVar(&lane, "lane", "", "Only show cards in this lane")
+	cmd.Flags().BoolVar(&mine, "mine", false, "Only show cards assigned to the authenticated agent")
+	cmd.Flags().StringVar(&assignee, "assignee", "", "Only show cards assigned to this agent")
+	cmd.Flags().StringVar(&stale, "stale", "",
+		"Only show cards not updated for this long, e.g. 3d or 12h (cards without updated_at are left out)")
+	cmd.MarkFlagsMutuallyExclusive("mine", "assignee")
+	cmd.Flags().StringVar(&groupBy, "group-by", "", "Group the table under lane or priority headers")
+	cmd.Flags().StringSliceVar(&columns, "columns", nil,
+		"Card fields to show as table columns, in order (e.g. id,title,assignee,updated_at)")
+	cmd.MarkFlagsMutuallyExclusive("columns", "group-by")
+	cmd.Flags().BoolVar(&count, "count", false, "Print only the number of matching cards")
+	cmd.MarkFlagsMutuallyExclusive("count", "group-by")
+	cmd.MarkFlagsMutuallyExclusive("count", "columns")
 	return cmd
 }
 
 func newPullContextCommand() *cobra.Command {
-	return &cobra.Command{
+	var refreshClaim bool
+
+	cmd := &cobra.Command{
 		Use:   "context",
 		Short: "Pull context for the current working card",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			// Read current card from .dea-context/.current-card
-			data, err := os.ReadFile(".dea-context/.current-card")
+			cardID, err := readCurrentCard()
 			if err != nil {
-				return fmt.Errorf("no current card set. Use `dea claim <card-id>` first")
+				return err
 			}
 
-			cardID := string(data)
-			if cardID == "" {
-				return fmt.Errorf("no current card set. Use `dea claim <card-id>` first")
+			if refreshClaim {
+				return refreshCardContext(cardID)
 			}
 
 			// Delegate to pull card.
 			pullCmd := newPullCardCommand()
+			pullCmd.SetContext(cmd.Context())
 			return pullCmd.RunE(pullCmd, []string{cardID})
 		},
 	}
+
+	cmd.Flags().BoolVar(&refreshClaim, "refresh-claim", false,
+		"Re-fetch the card and show what changed since the cached context")
+	return cmd
+}
+
+// refreshCardContext re-fetches a card and prints what changed relative to
+// the cached card-<id>.json.
+func refreshCardContext(cardID string) error {
+	mustLoadToken()
+
+	cachePath := cardContextFile(cardID)
+	cached, cacheErr := os.ReadFile(cachePath)
+	if cacheErr == nil {
+		// A cache that no longer parses is treated like no cache at all.
+		if _, err := extractCard(cached); err != nil {
+			cacheErr = err
+		}
+	}
+
+	card, err := pullCardContext(cardID)
+	if err != nil {
+		return err
+	}
+	printCardSummary(card)
+
+	if cacheErr != nil {
+		fmt.Println("No cached context to compare against.")
+		return nil
+	}
+	fresh, err := os.ReadFile(cachePath)
+	if err != nil {
+		return fmt.Errorf("failed to read context file: %w", err)
+	}
+
+	fmt.Println()
+	if printCardChanges(cached, fresh) == 0 {
+		fmt.Println("No changes since last pull.")
+	}
+	return nil
+}
+
+// cardCollections are the list fields of a card context whose new entries
+// --refresh-claim reports.
+var cardCollections = []struct{ field, label string }{
+	{"artifacts", "artifact"},
+	{"comments", "comment"},
+	{"linked_cards", "linked card"},
+}
+
+// printCardChanges compares two card context responses, printing watched
+// fields that differ and entries added to card collections. Returns the
+// number of changes printed.
+func printCardChanges(old, cur []byte) int {
+	changes := 0
+
+	oldCard, _ := extractCard(old)
+	curCard, _ := extractCard(cur)
+	prev, next := watchedValues(oldCard), watchedValues(curCard)
+	for _, field := range watchedCardFields {
+		if prev[field] != next[field] {
+			fmt.Printf("  %s: %s -> %s\n", field, prev[field], next[field])
+			changes++
+		}
+	}
+
+	for _, c := range cardCollections {
+		seen := make(map[string]bool)
+		for _, item := range contextList(old, c.field) {
+			seen[cardListKey(item)] = true
+		}
+		for _, item := range contextList(cur, c.field) {
+			if seen[cardListKey(item)] {
+				continue
+			}
+			fmt.Printf("  new %s: %s\n", c.label, cardListLabel(item))
+			changes++
+		}
+	}
+	return changes
+}
+
+// contextList returns the entries of a card context list field, which the
+// server places either on the card or alongside it in the envelope.
+func contextList(data []byte, field string) []map[string]interface{} {
+	if items, err := unwrapList(data, "card", field); err == nil {
+		return items
+	}
+	items, _ := unwrapList(data, field)
+	return items
+}
+
+// cardListKey identifies a list entry by its ID, falling back to its JSON
+// encoding for entries the server sends without one.
+func cardListKey(item map[string]interface{}) string {
+	if id := strField(item, "id", strField(item, "card_id", "")); id != "" {
+		return id
+	}
+	data, _ := json.Marshal(item)
+	return string(data)
+}
+
+// cardListLabel is the short description printed for a new list entry.
+func cardListLabel(item map[string]interface{}) string {
+	for _, key := range []string{"title", "file_name", "body", "text"} {
+		if v := strField(item, key, ""); v != "" {
+			if len(v) > 60 {
+				v = v[:57] + "..."
+			}
+			return v
+		}
+	}
+	return cardListKey(item)
+}
+
+// resolveProject picks the project for board commands. Precedence:
+// --project flag, then $DEA_PROJECT, then default_project in config.
+func resolveProject(flagValue string) (string, error) {
+	for _, v := range []string{flagValue, os.Getenv("DEA_PROJECT"), cfg.DefaultProject} {
+		if v = strings.TrimSpace(v); v != "" {
+			return v, nil
+		}
+	}
+	return "", fmt.Errorf("no project configured. Use --project <slug>, set DEA_PROJECT, or set default_project in config")
+}
+
+// fetchBoard lists all cards for a project across every page.
+func fetchBoard(projectID string) ([]map[string]interface{}, error) {
+	pages, err := apiClient.GetAll(api.PathCards + "?project_id=" + url.QueryEscape(projectID))
+	if err != nil {
+		return nil, err
+	}
+
+	var cards []map[string]interface{}
+	for _, body := range pages {
+		page, err := unwrapList(body, "cards")
+		if err != nil {
+			return nil, fmt.Errorf("unexpected board response: %w", err)
+		}
+		cards = append(cards, page...)
+	}
+	return cards, nil
+}
+
+// filterCards returns the cards for which keep returns true.
+func filterCards(cards []map[string]interface{}, keep func(map[string]interface{}) bool) []map[string]interface{} {
+	filtered := make([]map[string]interface{}, 0, len(cards))
+	for _, c := range cards {
+		if keep(c) {
+			filtered = append(filtered, c)
+		}
+	}
+	return filtered
+}
+
+// cardInLane reports whether the card is in lane, accepting either the CLI
+// ("in-progress") or DB ("in_progress") spelling.
+func cardInLane(card map[string]interface{}, lane string) bool {
+	return sameLane(strField(card, "lane", strField(card, "status", "")), lane)
+}
+
+// cardAssignee returns the agent a card is assigned to, or "".
+func cardAssignee(card map[string]interface{}) string {
+	return strField(card, "assignee", strField(card, "agent_id", strField(card, "claimed_by", "")))
 }
 
 func printCardSummary(card map[string]interface{}) {
@@ -167,7 +657,37 @@ func printCardSummary(card map[string]interface{}) {
 	}
 }
 
+// printLinkedCards lists the cards linked to cardID, read from the context
+// pullCardContext saved.
+func printLinkedCards(cardID string) {
+	data, err := os.ReadFile(cardContextFile(cardID))
+	if err != nil {
+		return
+	}
+	for i, link := range contextList(data, "linked_cards") {
+		label := "  Linked:   "
+		if i > 0 {
+			label = "            "
+		}
+		id := strField(link, "id", strField(link, "card_id", strField(link, "target_card_id", "?")))
+		line := id
+		if rel := strField(link, "relation", ""); rel != "" {
+			line = strings.ReplaceAll(rel, "_", " ") + " " + id
+		}
+		if title := strField(link, "title", ""); title != "" {
+			line += "  " + title
+		}
+		fmt.Println(label + line)
+	}
+}
+
 func printCardTable(cards []map[string]interface{}) {
+	printCardTableHighlighting(cards, "")
+}
+
+// printCardTableHighlighting prints the card table with case-insensitive
+// matches of query in titles highlighted. An empty query highlights nothing.
+func printCardTableHighlighting(cards []map[string]interface{}, query string) {
 	fmt.Printf("%-20s  %-30s  %-12s  %-8s\n", "ID", "TITLE", "LANE", "PRIORITY")
 	fmt.Printf("%-20s  %-30s  %-12s  %-8s\n",
 		"--------------------", "------------------------------", "------------", "--------")
@@ -181,9 +701,150 @@ func printCardTable(cards []map[string]interface{}) {
 		if len(title) > 30 {
 			title = title[:27] + "..."
 		}
+		// Pad before highlighting so escape codes don't count toward the
+		// column width.
+		title = highlightMatches(fmt.Sprintf("%-30s", title), query)
+
+		fmt.Printf("%-20s  %s  %-12s  %-8s\n", id, title, lane, priority)
+	}
+}
+
+// maxColumnWidth caps --columns cells so one long field doesn't push the rest
+// of the table off screen.
+const maxColumnWidth = 40
+
+// printCardColumns prints a table of the named card fields. Fields a card
+// doesn't have are left blank.
+func printCardColumns(cards []map[string]interface{}, columns []string) {
+	rows := make([][]string, len(cards))
+	widths := make([]int, len(columns))
+	for i, col := range columns {
+		columns[i] = strings.TrimSpace(col)
+		widths[i] = len(columns[i])
+	}
+	for r, card := range cards {
+		rows[r] = make([]string, len(columns))
+		for i, col := range columns {
+			v := columnValue(card, col)
+			if len(v) > maxColumnWidth {
+				v = v[:maxColumnWidth-3] + "..."
+			}
+			rows[r][i] = v
+			if len(v) > widths[i] {
+				widths[i] = len(v)
+			}
+		}
+	}
+
+	printRow := func(cells []string) {
+		line := make([]string, len(cells))
+		for i, c := range cells {
+			line[i] = fmt.Sprintf("%-*s", widths[i], c)
+		}
+		fmt.Println(strings.TrimRight(strings.Join(line, "  "), " "))
+	}
+
+	header := make([]string, len(columns))
+	rule := make([]string, len(columns))
+	for i, col := range columns {
+		header[i] = strings.ToUpper(col)
+		rule[i] = strings.Repeat("-", widths[i])
+	}
+	printRow(header)
+	printRow(rule)
+	for _, row := range rows {
+		printRow(row)
+	}
+}
+
+// columnValue renders a card field as a table cell.
+func columnValue(card map[string]interface{}, key string) string {
+	switch v := card[key].(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case []interface{}:
+		parts := make([]string, len(v))
+		for i, item := range v {
+			parts[i] = fmt.Sprint(item)
+		}
+		return strings.Join(parts, ",")
+	default:
+		return fmt.Sprint(v)
+	}
+}
+
+// priorityOrder is the display order of priority groups; others follow in
+// the order they first appear.
+var priorityOrder = []string{"critical", "urgent", "high", "normal", "medium", "low"}
+
+// printCardGroups prints cards under one header per lane or priority, with
+// the number of cards in each.
+func printCardGroups(cards []map[string]interface{}, groupBy string) {
+	key := func(c map[string]interface{}) string {
+		if groupBy == "priority" {
+			return strings.ToLower(strField(c, "priority", "normal"))
+		}
+		return toDisplayLane(strField(c, "lane", strField(c, "status", "unknown")))
+	}
+
+	order := priorityOrder
+	if groupBy == "lane" {
+		order = validStages
+	}
+
+	groups := make(map[string][]map[string]interface{})
+	var seen []string
+	for _, c := range cards {
+		k := key(c)
+		if _, ok := groups[k]; !ok {
+			seen = append(seen, k)
+		}
+		groups[k] = append(groups[k], c)
+	}
 
-		fmt.Printf("%-20s  %-30s  %-12s  %-8s\n", id, title, lane, priority)
+	var keys []string
+	for _, k := range order {
+		if _, ok := groups[k]; ok {
+			keys = append(keys, k)
+		}
+	}
+	for _, k := range seen {
+		if !containsString(order, k) {
+			keys = append(keys, k)
+		}
+	}
+
+	for i, k := range keys {
+		if i > 0 {
+			fmt.Println()
+		}
+		fmt.Printf("%s (%d)\n", strings.ToUpper(k), len(groups[k]))
+		for _, card := range groups[k] {
+			id := strField(card, "id", strField(card, "card_id", "?"))
+			title := strField(card, "title", "(no title)")
+			if len(title) > 30 {
+				title = title[:27] + "..."
+			}
+			detail := strField(card, "priority", "normal")
+			if groupBy == "priority" {
+				detail = strField(card, "lane", strField(card, "status", "?"))
+			}
+			fmt.Printf("  %-20s  %-30s  %s\n", id, title, detail)
+		}
+	}
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
 	}
+	return false
 }
 
 func strField(m map[string]interface{}, key, defaultVal string) string {
@@ -0,0 +1,178 @@
+package commands
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/dea-exmachina/dea-cli/internal/config"
+)
+
+// withAPIServer points apiClient at a server answering every request with
+// status and body, and contextDir at a temp dir, for the test.
+func withAPIServer(t *testing.T, status int, body string) {
+	t.Helper()
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		w.Write([]byte(body))
+	}))
+	oldClient, oldDir := apiClient, contextDir
+	apiClient = api.NewClient(srv.URL, 5, staticToken("tok"))
+	contextDir = filepath.Join(t.TempDir(), ".dea-context")
+	t.Cleanup(func() {
+		srv.Close()
+		apiClient, contextDir = oldClient, oldDir
+	})
+}
+
+func TestExtractCard(t *testing.T) {
+	for _, tc := range []struct {
+		name    string
+		body    string
+		wantErr string
+	}{
+		{"bare card", `{"id":"c1","title":"T","lane":"ready"}`, ""},
+		{"data and card", `{"data":{"card":{"id":"c1","title":"T","status":"ready"}}}`, ""},
+		{"card_id", `{"data":{"card_id":"c1","title":"","lane":"ready"}}`, ""},
+		{"error string", `{"error":"card not found"}`, "server returned an error: card not found"},
+		{"error object", `{"error":{"message":"access denied"}}`, "server returned an error: access denied"},
+		{"error without message", `{"error":{"code":42}}`, "server returned an error: unknown error"},
+		{"missing fields", `{"data":{"card":{"id":"c1"}}}`, "missing card field(s): title, lane"},
+		{"empty object", `{}`, "missing card field(s): id, title, lane"},
+		{"array", `[{"id":"c1"}]`, "not a JSON object"},
+		{"html", `<html>502</html>`, "not a JSON object"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			card, err := extractCard([]byte(tc.body))
+			if tc.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				if strField(card, "id", strField(card, "card_id", "")) != "c1" {
+					t.Errorf("got %v, want card c1", card)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+				t.Errorf("err = %v, want %q", err, tc.wantErr)
+			}
+		})
+	}
+}
+
+func TestPullCardContextRejectsErrorBody(t *testing.T) {
+	withAPIServer(t, http.StatusOK, `{"error":"card not found"}`)
+
+	// A context saved by an earlier pull must survive the bad response.
+	if err := ensureContextDir(); err != nil {
+		t.Fatal(err)
+	}
+	cached := `{"data":{"card":{"id":"c1","title":"T","lane":"ready"}}}`
+	if err := os.WriteFile(cardContextFile("c1"), []byte(cached), 0644); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err := pullCardContext("c1")
+	if err == nil || !strings.Contains(err.Error(), "card not found") {
+		t.Fatalf("err = %v, want the server's error", err)
+	}
+	data, err := os.ReadFile(cardContextFile("c1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != cached {
+		t.Errorf("context file overwritten with %s", data)
+	}
+}
+
+func TestPullCardContextWritesValidCard(t *testing.T) {
+	body := `{"data":{"card":{"id":"c1","title":"T","lane":"ready"}}}`
+	withAPIServer(t, http.StatusOK, body)
+
+	card, err := pullCardContext("c1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if card["title"] != "T" {
+		t.Errorf("card = %v", card)
+	}
+	data, err := os.ReadFile(cardContextFile("c1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != body {
+		t.Errorf("context file = %s, want the response body", data)
+	}
+}
+
+func TestResolveProjectPrecedence(t *testing.T) {
+	old := cfg
+	defer func() { cfg = old }()
+
+	for _, tc := range []struct {
+		name   string
+		flag   string
+		env    string
+		config string
+		want   string
+	}{
+		{"flag wins", "from-flag", "from-env", "from-config", "from-flag"},
+		{"env over config", "", "from-env", "from-config", "from-env"},
+		{"config last", "", "", "from-config", "from-config"},
+		{"blank flag skipped", "  ", "from-env", "from-config", "from-env"},
+		{"blank env skipped", "", " ", "from-config", "from-config"},
+		{"values trimmed", " from-flag ", "", "", "from-flag"},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			t.Setenv("DEA_PROJECT", tc.env)
+			cfg = &config.Config{DefaultProject: tc.config}
+
+			got, err := resolveProject(tc.flag)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if got != tc.want {
+				t.Errorf("resolveProject = %q, want %q", got, tc.want)
+			}
+		})
+	}
+
+	t.Run("none set", func(t *testing.T) {
+		t.Setenv("DEA_PROJECT", "")
+		cfg = &config.Config{}
+		if _, err := resolveProject(""); err == nil || !strings.Contains(err.Error(), "no project configured") {
+			t.Errorf("err = %v, want no project configured", err)
+		}
+	})
+}
+
+func TestFetchBoardUnknownProject(t *testing.T) {
+	withAPIServer(t, http.StatusNotFound, `{"error":"project not found"}`)
+
+	_, err := fetchBoard("nope")
+	if got := api.StatusCode(err); got != http.StatusNotFound {
+		t.Errorf("StatusCode(%v) = %d, want 404", err, got)
+	}
+}
+
+func TestPullBoardMineNeedsAgentID(t *testing.T) {
+	requested := withLoggedIn(t)
+	cfg.DefaultProject = "p1"
+	t.Setenv("DEA_AGENT_ID", "")
+
+	cmd := newPullBoardCommand()
+	cmd.SetArgs([]string{"--mine"})
+	cmd.SilenceUsage = true
+	err := cmd.Execute()
+	if err == nil || !strings.Contains(err.Error(), "cannot determine your agent ID") {
+		t.Fatalf("err = %v, want cannot determine your agent ID", err)
+	}
+	if paths := requested(); len(paths) != 0 {
+		t.Errorf("requested %v before failing", paths)
+	}
+}
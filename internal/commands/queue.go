@@ -0,0 +1,282 @@
+package commands
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/queue"
+	"github.com/spf13/cobra"
+)
+
+func newQueueCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "queue",
+		Short: "Inspect and move the offline request queue",
+	}
+
+	cmd.AddCommand(newQueueListCommand())
+	cmd.AddCommand(newQueueFlushCommand())
+	cmd.AddCommand(newQueueExportCommand())
+	cmd.AddCommand(newQueueImportCommand())
+	cmd.AddCommand(newQueueStatsCommand())
+
+	return cmd
+}
+
+func newQueueListCommand() *cobra.Command {
+	var out outputOptions
+
+	cmd := &cobra.Command{
+		Use:   "list",
+		Short: "List queued requests, oldest first",
+		Example: `  # Show what will be sent on the next connection
+  dea queue list
+
+  # Report pending work to an orchestrator, bodies included
+  dea queue list --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := offQueue.List()
+			if err != nil {
+				return fmt.Errorf("failed to load queue: %w", err)
+			}
+			if items == nil {
+				items = []queue.QueuedRequest{}
+			}
+			sort.SliceStable(items, func(i, j int) bool {
+				return items[i].QueuedAt.Before(items[j].QueuedAt)
+			})
+
+			if ok, err := out.render(items); ok || err != nil {
+				return err
+			}
+			if len(items) == 0 {
+				fmt.Println("Queue is empty.")
+				return nil
+			}
+
+			now := time.Now()
+			fmt.Printf("%-20s  %-6s  %-8s  %s\n", "ID", "METHOD", "AGE", "PATH")
+			for _, item := range items {
+				fmt.Printf("%-20s  %-6s  %-8s  %s\n", item.ID, item.Method, formatAge(now.Sub(item.QueuedAt)), item.Path)
+			}
+			return nil
+		},
+	}
+
+	addOutputFlags(cmd, &out)
+	return cmd
+}
+
+func newQueueFlushCommand() *cobra.Command {
+	var pruneAfter int
+
+	cmd := &cobra.Command{
+		Use:   "flush",
+		Short: "Send queued requests now",
+		Long: `Replay queued requests against the API, oldest first, removing each one
+that succeeds. Flushing stops, keeping the rest, if the endpoint is still
+unreachable, rate limits the CLI, rejects the token or fails with a 5xx.
+
+A request the API rejects (4xx) is removed at once. With --prune-after N it
+is kept and retried on later flushes instead, and removed once it has failed
+(4xx or 5xx) more than N times, so one bad request can't block the queue
+forever.`,
+		Example: `  # Send everything queued while offline
+  dea queue flush
+
+  # Give each request three tries before dropping it
+  dea queue flush --prune-after 3`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			if pruneAfter < 0 {
+				return fmt.Errorf("--prune-after must not be negative")
+			}
+			if offQueue.Len() == 0 {
+				fmt.Println("Queue is empty.")
+				return nil
+			}
+			mustLoadToken()
+
+			flushed, pruned, err := queue.Flush(offQueue, apiClient, pruneAfter)
+			if err != nil {
+				return err
+			}
+			fmt.Printf("Flushed %d request(s)", flushed)
+			if pruned > 0 {
+				fmt.Printf(", pruned %d", pruned)
+			}
+			fmt.Printf(". %d still queued.\n", offQueue.Len())
+			return nil
+		},
+	}
+
+	cmd.Flags().IntVar(&pruneAfter, "prune-after", 0,
+		"Keep rejected requests for retry, removing each after it has failed more than this many times (0: remove rejected requests at once)")
+	return cmd
+}
+
+func newQueueExportCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "export <file>",
+		Short: "Write all queued requests to a file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+
+			items, err := offQueue.List()
+			if err != nil {
+				return fmt.Errorf("failed to load queue: %w", err)
+			}
+
+			data, err := json.MarshalIndent(items, "", "  ")
+			if err != nil {
+				return err
+			}
+			// Queued bodies may carry secrets (e.g. vault writes).
+			if err := os.WriteFile(path, data, 0600); err != nil {
+				return fmt.Errorf("failed to write %s: %w", path, err)
+			}
+
+			fmt.Printf("Exported %d queued request(s) to %s.\n", len(items), path)
+			return nil
+		},
+	}
+}
+
+func newQueueImportCommand() *cobra.Command {
+	var replace bool
+
+	cmd := &cobra.Command{
+		Use:   "import <file>",
+		Short: "Merge queued requests from an exported file",
+		Long: `Merge queued requests from a file produced by ` + "`dea queue export`" + `.
+Requests whose ID is already queued are skipped. Use --replace to discard the
+current queue instead of merging.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			path := args[0]
+
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return fmt.Errorf("failed to read %s: %w", path, err)
+			}
+
+			// Reject anything that is not exactly a []QueuedRequest.
+			dec := json.NewDecoder(bytes.NewReader(data))
+			dec.DisallowUnknownFields()
+			var items []queue.QueuedRequest
+			if err := dec.Decode(&items); err != nil {
+				return fmt.Errorf("%s is not a valid queue export: %w", path, err)
+			}
+			for _, item := range items {
+				if err := item.Validate(); err != nil {
+					return fmt.Errorf("%s is not a valid queue export: %w", path, err)
+				}
+			}
+
+			added, err := offQueue.Import(items, replace)
+			if err != nil {
+				return fmt.Errorf("failed to import queue: %w", err)
+			}
+
+			if skipped := len(items) - added; skipped > 0 {
+				fmt.Printf("Imported %d queued request(s), skipped %d duplicate(s).\n", added, skipped)
+			} else {
+				fmt.Printf("Imported %d queued request(s).\n", added)
+			}
+			return nil
+		},
+	}
+
+	cmd.Flags().BoolVar(&replace, "replace", false, "Replace the current queue instead of merging")
+	return cmd
+}
+
+// queueAgeBuckets are the upper bounds of the `queue stats` age histogram.
+// Items older than the last bound fall into a final open-ended bucket.
+var queueAgeBuckets = []struct {
+	label string
+	max   time.Duration
+}{
+	{"< 1h", time.Hour},
+	{"1h-1d", 24 * time.Hour},
+	{"1d-7d", 7 * 24 * time.Hour},
+}
+
+func newQueueStatsCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "stats",
+		Short: "Summarize the offline queue: size, age and endpoints",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			items, err := offQueue.List()
+			if err != nil {
+				return fmt.Errorf("failed to load queue: %w", err)
+			}
+			if len(items) == 0 {
+				fmt.Println("Queue is empty.")
+				return nil
+			}
+
+			now := time.Now()
+			oldest, newest := items[0].QueuedAt, items[0].QueuedAt
+			ages := make([]int, len(queueAgeBuckets)+1)
+			endpoints := map[string]int{}
+			for _, item := range items {
+				if item.QueuedAt.Before(oldest) {
+					oldest = item.QueuedAt
+				}
+				if item.QueuedAt.After(newest) {
+					newest = item.QueuedAt
+				}
+
+				age := now.Sub(item.QueuedAt)
+				bucket := len(queueAgeBuckets)
+				for i, b := range queueAgeBuckets {
+					if age < b.max {
+						bucket = i
+						break
+					}
+				}
+				ages[bucket]++
+
+				endpoints[item.Method+" "+item.Path]++
+			}
+
+			fmt.Printf("Queued requests: %d\n", len(items))
+			fmt.Printf("Oldest:          %s (%s ago)\n", oldest.Local().Format(time.RFC3339), formatAge(now.Sub(oldest)))
+			fmt.Printf("Newest:          %s (%s ago)\n", newest.Local().Format(time.RFC3339), formatAge(now.Sub(newest)))
+
+			fmt.Println("\nBy age:")
+			for i, n := range ages {
+				label := "> 7d"
+				if i < len(queueAgeBuckets) {
+					label = queueAgeBuckets[i].label
+				}
+				fmt.Printf("  %-6s %d\n", label, n)
+			}
+
+			keys := make([]string, 0, len(endpoints))
+			for k := range endpoints {
+				keys = append(keys, k)
+			}
+			// Busiest endpoints first, then alphabetically for a stable order.
+			sort.Slice(keys, func(i, j int) bool {
+				if endpoints[keys[i]] != endpoints[keys[j]] {
+					return endpoints[keys[i]] > endpoints[keys[j]]
+				}
+				return keys[i] < keys[j]
+			})
+			fmt.Println("\nBy endpoint:")
+			for _, k := range keys {
+				fmt.Printf("  %5d  %s\n", endpoints[k], k)
+			}
+			return nil
+		},
+	}
+}
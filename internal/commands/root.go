@@ -1,8 +1,14 @@
 package commands
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
 	"github.com/dea-exmachina/dea-cli/internal/auth"
@@ -13,24 +19,117 @@ import (
 
 var (
 	// Global flags
-	endpointFlag string
+	endpointFlag  string
+	noQueueFlag   bool
+	timingFlag    bool
+	logFormatFlag string
+
+	endpointFromTokenFlag bool
+	agentIDFlag           string
+	verboseFlag           bool
+	maxBodySizeFlag       string
 
 	// Shared instances (initialized in initGlobals)
 	cfg        *config.Config
 	tokenStore *auth.TokenStore
 	apiClient  *api.Client
 	offQueue   *queue.Queue
+	timings    *api.Timings
+
+	// commandCtx is the running command's context, cancelled by the first
+	// Ctrl-C. API clients send requests under it.
+	commandCtx context.Context
+
+	// maxBodySize is the API response size limit from --max-body-size or
+	// config; zero keeps the client default.
+	maxBodySize int64
+
+	// stopAutoRefresh stops the background token refresher.
+	stopAutoRefresh func()
 )
 
+// exitError makes the process exit with a specific code. A nil err exits
+// without printing anything.
+type exitError struct {
+	code int
+	err  error
+}
+
+func (e *exitError) Error() string {
+	if e.err == nil {
+		return fmt.Sprintf("exit status %d", e.code)
+	}
+	return e.err.Error()
+}
+
+func (e *exitError) Unwrap() error {
+	return e.err
+}
+
+// exitInterrupted is the conventional status for a process stopped by SIGINT.
+const exitInterrupted = 130
+
+// interruptContext returns a context cancelled by the first Ctrl-C, so
+// commands can stop their loops and save state before exiting. A second
+// Ctrl-C kills the process immediately. interrupted reports whether the
+// first one arrived.
+func interruptContext() (ctx context.Context, interrupted func() bool) {
+	ctx, cancel := context.WithCancel(context.Background())
+	sigs := make(chan os.Signal, 1)
+	signal.Notify(sigs, os.Interrupt)
+	go func() {
+		<-sigs
+		signal.Stop(sigs)
+		cancel()
+	}()
+	return ctx, func() bool { return ctx.Err() != nil }
+}
+
 // Execute is the entry point called from main.go.
 func Execute(version, commit, date string) {
+	cleanupOldBinary()
+
+	ctx, interrupted := interruptContext()
+
 	root := newRootCommand(version, commit, date)
-	if err := root.Execute(); err != nil {
-		fmt.Fprintln(os.Stderr, err)
+	handled, err := runPlugin(ctx, root, os.Args[1:])
+	if !handled {
+		err = root.ExecuteContext(ctx)
+	}
+	printTimingSummary()
+	if interrupted() {
+		if err != nil {
+			reportError(err)
+		}
+		os.Exit(exitInterrupted)
+	}
+	if err != nil {
+		var ee *exitError
+		if errors.As(err, &ee) {
+			if ee.err != nil {
+				reportError(ee.err)
+			}
+			os.Exit(ee.code)
+		}
+		reportError(err)
 		os.Exit(1)
 	}
 }
 
+// reportError logs the error a command failed with. API errors show a
+// summary of the response body; --verbose adds the full body. A 404 for the
+// current card is explained as a stale pointer, with an offer to clear it.
+func reportError(err error) {
+	err = explainStaleCurrentCard(err)
+	logError("%v", err)
+
+	var apiErr *api.APIError
+	if verboseFlag && errors.As(err, &apiErr) && len(apiErr.Body) > 0 {
+		logInfo("response body:\n%s", strings.TrimSpace(string(apiErr.Body)))
+	}
+	offerClearCurrentCard(err)
+}
+
 func newRootCommand(version, commit, date string) *cobra.Command {
 	root := &cobra.Command{
 		Use:   "dea",
@@ -39,7 +138,11 @@ func newRootCommand(version, commit, date string) *cobra.Command {
 It communicates exclusively with Edge Function endpoints using scoped workspace JWTs.`,
 		Version: fmt.Sprintf("%s (commit: %s, built: %s)", version, commit, date),
 		PersistentPreRunE: func(cmd *cobra.Command, args []string) error {
-			return initGlobals()
+			logCommand = strings.TrimPrefix(cmd.CommandPath(), cmd.Root().Name()+" ")
+			if logFormatFlag != logFormatText && logFormatFlag != logFormatJSON {
+				return fmt.Errorf("invalid --log-format %q (use text or json)", logFormatFlag)
+			}
+			return initGlobals(cmd.Context())
 		},
 		SilenceUsage:  true,
 		SilenceErrors: true,
@@ -47,25 +150,54 @@ It communicates exclusively with Edge Function endpoints using scoped workspace
 
 	// Global flags
 	root.PersistentFlags().StringVar(&endpointFlag, "endpoint", "", "Override the API endpoint URL")
+	root.PersistentFlags().BoolVar(&endpointFromTokenFlag, "endpoint-from-token", false,
+		"Send requests to the endpoint the stored token was issued for instead of the configured one")
+	root.MarkFlagsMutuallyExclusive("endpoint", "endpoint-from-token")
+	root.PersistentFlags().StringVar(&agentIDFlag, "agent-id", "",
+		"Act as this agent instead of the token's own (default: $DEA_AGENT_ID)")
+	root.PersistentFlags().BoolVar(&noQueueFlag, "no-queue", false,
+		"Fail on network errors instead of queueing writes for later (default is to queue offline)")
+	root.PersistentFlags().BoolVar(&timingFlag, "timing", false, "Print the duration of each API call to stderr")
+	root.PersistentFlags().StringVar(&logFormatFlag, "log-format", logFormatText,
+		"Format of messages on stderr: text, or json for one JSON object per line")
+	root.PersistentFlags().BoolVar(&verboseFlag, "verbose", false, "Show full API response bodies in error messages")
+	root.PersistentFlags().StringVar(&maxBodySizeFlag, "max-body-size", "",
+		"Fail API calls whose response is larger than this, e.g. 100MB (default: max_body_size in config, else 64MB)")
 
 	// Register all subcommands
 	root.AddCommand(newAuthCommand())
 	root.AddCommand(newPullCommand())
 	root.AddCommand(newClaimCommand())
+	root.AddCommand(newCardCommand())
 	root.AddCommand(newTransitionCommand())
 	root.AddCommand(newArtifactCommand())
 	root.AddCommand(newSignalCommand())
 	root.AddCommand(newDoneCommand())
 	root.AddCommand(newWorkspaceCommand())
 	root.AddCommand(newAutoCommand())
+	root.AddCommand(newVaultCommand())
+	root.AddCommand(newQueueCommand())
+	root.AddCommand(newStatusCommand())
+	root.AddCommand(newExamplesCommand())
 	root.AddCommand(newUpdateCommand(version, commit, date))
 
 	return root
 }
 
 // initGlobals loads config and initializes shared API client + queue.
-func initGlobals() error {
-	var err error
+// Requests made through the client are aborted when ctx is cancelled.
+func initGlobals(ctx context.Context) error {
+	commandCtx = ctx
+
+	// Carry over state from ~/.dea when DEA_HOME or XDG_CONFIG_HOME moved it.
+	migrated, err := config.MigrateLegacy()
+	for _, line := range migrated {
+		logInfo("migrated %s", line)
+	}
+	if err != nil {
+		logWarn("legacy config migration incomplete: %v", err)
+	}
+
 	cfg, err = config.Load()
 	if err != nil {
 		return fmt.Errorf("failed to load config: %w", err)
@@ -76,31 +208,141 @@ func initGlobals() error {
 		cfg.Endpoint = endpointFlag
 	}
 
+	auth.Warnf = logWarn
+	queue.Warnf = logWarn
 	tokenStore = auth.NewTokenStore()
-	apiClient = api.NewClient(cfg.Endpoint, cfg.TimeoutSeconds, tokenStore)
-	offQueue = queue.New()
 
-	// Start background auto-refresh. Bridge api.TokenResponse -> auth.TokenData.
-	auth.StartAutoRefresh(tokenStore, func(currentToken string) (*auth.TokenData, error) {
-		resp, err := apiClient.RefreshToken(currentToken)
-		if err != nil {
-			return nil, err
+	// Or follow the endpoint recorded with the token at login.
+	if endpointFromTokenFlag {
+		if token := tokenStore.Load(); token != nil && token.Endpoint != "" {
+			cfg.Endpoint = token.Endpoint
+		}
+	}
+
+	if timingFlag {
+		timings = api.NewTimings(&logWriter{})
+	}
+
+	value, source := maxBodySizeFlag, "--max-body-size"
+	if value == "" {
+		value, source = cfg.MaxBodySize, "max_body_size"
+	}
+	if value != "" {
+		if maxBodySize, err = parseSize(value); err != nil {
+			return fmt.Errorf("%s: %w", source, err)
 		}
-		existing := tokenStore.Load()
-		endpoint := cfg.Endpoint
-		if existing != nil {
-			endpoint = existing.Endpoint
+		if maxBodySize <= 0 {
+			return fmt.Errorf("%s must be greater than zero", source)
 		}
-		return &auth.TokenData{
-			WorkspaceToken: resp.WorkspaceToken,
-			TokenType:      resp.TokenType,
-			ExpiresAt:      resp.ExpiresAt,
-			WorkspaceID:    resp.WorkspaceID,
-			AgentID:        resp.AgentID,
-			Endpoint:       endpoint,
-		}, nil
-	})
+	}
+
+	workspaceID := ""
+	if token := tokenStore.Load(); token != nil {
+		_, workspaceID, _ = tokenIdentity(token)
+	}
+	if contextDir, err = resolveContextDir(cfg.ContextDir, workspaceID); err != nil {
+		return err
+	}
+
+	apiClient = newAPIClient(cfg.Endpoint)
+	offQueue = queue.New()
+
+	// Start background auto-refresh.
+	stopAutoRefresh = auth.StartAutoRefresh(tokenStore, refreshToken)
 
 	return nil
 }
 
+// refreshToken is the auth.RefreshFunc used by the token refresher. It
+// bridges api.TokenResponse -> auth.TokenData.
+func refreshToken(currentToken string) (*auth.TokenData, error) {
+	resp, err := apiClient.RefreshToken(currentToken)
+	if err != nil {
+		return nil, err
+	}
+	existing := tokenStore.Load()
+	endpoint := cfg.Endpoint
+	if existing != nil {
+		endpoint = existing.Endpoint
+	}
+	return &auth.TokenData{
+		WorkspaceToken: resp.WorkspaceToken,
+		TokenType:      resp.TokenType,
+		ExpiresAt:      resp.ExpiresAt,
+		WorkspaceID:    resp.WorkspaceID,
+		AgentID:        resp.AgentID,
+		Endpoint:       endpoint,
+	}, nil
+}
+
+// reauthMu serializes 401 recovery so concurrent requests that all see the
+// same expired token refresh it only once.
+var reauthMu sync.Mutex
+
+// reauthenticate refreshes and saves the token after the API rejected
+// expired. If another request has already replaced it, there is nothing to
+// do and the caller retries with the new one.
+func reauthenticate(expired string) error {
+	reauthMu.Lock()
+	defer reauthMu.Unlock()
+
+	if current := tokenStore.GetToken(); current != expired {
+		if current == "" {
+			return fmt.Errorf("not authenticated")
+		}
+		return nil
+	}
+
+	newToken, err := refreshToken(expired)
+	if err != nil {
+		return err
+	}
+	if err := tokenStore.Save(newToken); err != nil {
+		return fmt.Errorf("failed to save refreshed token: %w", err)
+	}
+	logInfo("token was rejected; refreshed it and retrying")
+	return nil
+}
+
+// newAPIClient builds an API client for endpoint with the options selected by
+// global flags and config.
+func newAPIClient(endpoint string) *api.Client {
+	opts := []api.Option{api.WithNotifier(logInfo), api.WithContext(commandCtx)}
+	if cfg.RefreshOnUnauthorized {
+		opts = append(opts, api.WithReauth(reauthenticate))
+	}
+	if maxBodySize > 0 {
+		opts = append(opts, api.WithMaxResponseSize(maxBodySize))
+	}
+	if cfg.RequestsPerSecond > 0 {
+		opts = append(opts, api.WithRateLimit(cfg.RequestsPerSecond))
+	}
+	if cfg.MaxIdleConns > 0 || cfg.IdleConnTimeoutSeconds > 0 {
+		opts = append(opts, api.WithConnectionPool(cfg.MaxIdleConns,
+			time.Duration(cfg.IdleConnTimeoutSeconds)*time.Second))
+	}
+	if len(cfg.Headers) > 0 {
+		headers := make(map[string]string, len(cfg.Headers))
+		for k, v := range cfg.Headers {
+			headers[k] = os.ExpandEnv(v)
+		}
+		opts = append(opts, api.WithHeaders(headers))
+	}
+	if timings != nil {
+		opts = append(opts, api.WithTimings(timings))
+	}
+	return api.NewClient(endpoint, cfg.TimeoutSeconds, tokenStore, opts...)
+}
+
+// printTimingSummary reports min/avg/max when --timing saw several calls.
+func printTimingSummary() {
+	if timings == nil {
+		return
+	}
+	count, min, avg, max := timings.Summary()
+	if count < 2 {
+		return
+	}
+	logInfo("[timing] %d calls: min %s, avg %s, max %s", count,
+		min.Round(time.Millisecond), avg.Round(time.Millisecond), max.Round(time.Millisecond))
+}
@@ -1,7 +1,11 @@
 package commands
 
 import (
+	"bufio"
+	"encoding/json"
 	"fmt"
+	"os"
+	"os/exec"
 	"strings"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
@@ -12,9 +16,14 @@ var validSignalTypes = []string{"discovery", "correction", "friction", "pattern"
 
 func newSignalCommand() *cobra.Command {
 	var (
-		cardID      string
-		signalType  string
-		content     string
+		cardID     string
+		signalType string
+		content    string
+		gitRange   string
+		attach     string
+		dryRun     bool
+		stream     bool
+		batchSize  int
 	)
 
 	cmd := &cobra.Command{
@@ -22,14 +31,65 @@ func newSignalCommand() *cobra.Command {
 		Short: "Emit a learning signal for a card",
 		Long: fmt.Sprintf("Emit a learning signal. Valid types: %s",
 			strings.Join(validSignalTypes, ", ")),
+		Example: `  # Record friction on the current card
+  dea signal --card current --type friction --content "CI takes 20 minutes to start"
+
+  # Back a discovery with the log that shows it
+  dea signal --card current --type discovery --content "Cache misses on every cold start" --attach cold-start.log
+
+  # Emit signals tagged in commit messages ("discovery: ...") on this branch
+  dea signal --from-git main..HEAD
+
+  # Check what --from-git would send without sending it
+  dea signal --from-git main..HEAD --dry-run
+
+  # Pipe NDJSON signals from a long-running agent, 100 per request
+  my-agent | dea signal --stream --batch-size 100`,
 		RunE: func(cmd *cobra.Command, args []string) error {
-			mustLoadToken()
+			if !dryRun {
+				mustLoadToken()
+			}
+
+			if gitRange != "" {
+				return emitGitSignals(cardID, gitRange, dryRun)
+			}
+			if stream {
+				if batchSize < 1 {
+					return fmt.Errorf("--batch-size must be at least 1")
+				}
+				// Lines may name their own card; --card (or the current
+				// card, if any) fills in the rest.
+				if cardID != "" {
+					resolved, err := resolveCardID(cardID)
+					if err != nil {
+						return err
+					}
+					cardID = resolved
+				} else if current, err := readCurrentCard(); err == nil {
+					cardID = current
+				}
+				if signalType == "" {
+					signalType = cfg.DefaultSignalType
+				}
+				return streamSignals(cmd.Context(), cmd.InOrStdin(), cardID, signalType, batchSize, dryRun)
+			}
 
 			if cardID == "" {
 				return fmt.Errorf("--card is required")
 			}
+			cardID, err := resolveCardID(cardID)
+			if err != nil {
+				return err
+			}
 			if signalType == "" {
-				return fmt.Errorf("--type is required")
+				signalType = cfg.DefaultSignalType
+				if signalType == "" {
+					return fmt.Errorf("--type is required (or set default_signal_type in config)")
+				}
+				if !isValidSignalType(signalType) {
+					return fmt.Errorf("invalid default_signal_type %q in config. Valid types: %s",
+						signalType, strings.Join(validSignalTypes, ", "))
+				}
 			}
 			if content == "" {
 				return fmt.Errorf("--content is required")
@@ -40,40 +100,151 @@ func newSignalCommand() *cobra.Command {
 					signalType, strings.Join(validSignalTypes, ", "))
 			}
 
-			// API expects { signals: [...] } wrapper.
-			body := map[string]interface{}{
-				"signals": []map[string]string{
-					{
-						"card_id":     cardID,
-						"signal_type": signalType,
-						"content":     content,
-					},
-				},
+			entry := signalEntry(cardID, signalType, content)
+			if dryRun {
+				if attach != "" {
+					hash, err := fileSHA256(attach)
+					if err != nil {
+						return fmt.Errorf("cannot read %s: %w", attach, err)
+					}
+					entry["artifact_hash"] = hash
+				}
+				return printSignalsBody([]map[string]string{entry})
+			}
+
+			if attach != "" {
+				return emitSignalWithAttachment(entry, attach)
 			}
 
-			_, err := apiClient.Post(api.PathSignals, body)
+			queued, err := emitSignal(cardID, signalType, content)
 			if err != nil {
-				if isNetworkErr(err) {
-					if qErr := offQueue.Add("POST", api.PathSignals, body); qErr == nil {
-						fmt.Println("Queued offline. Will flush on next connection.")
-					}
-					return nil
-				}
 				return fmt.Errorf("failed to emit signal: %w", err)
 			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
 
 			fmt.Printf("Signal emitted: [%s] on card %s\n", signalType, cardID)
 			return nil
 		},
 	}
 
-	cmd.Flags().StringVar(&cardID, "card", "", "Card ID to emit the signal for")
-	cmd.Flags().StringVar(&signalType, "type", "", "Signal type (discovery|correction|friction|pattern)")
+	cmd.Flags().StringVar(&cardID, "card", "", "Card ID to emit the signal for (\"current\" for the current card)")
+	cmd.Flags().StringVar(&signalType, "type", "", "Signal type (discovery|correction|friction|pattern; default: default_signal_type in config)")
 	cmd.Flags().StringVar(&content, "content", "", "Signal content text")
+	cmd.Flags().StringVar(&gitRange, "from-git", "",
+		"Emit signals from commit message lines like \"friction: ...\" in a git range (e.g. main..HEAD)")
+	cmd.Flags().StringVar(&attach, "attach", "", "Push this file as an artifact of the card and reference it from the signal")
+	cmd.Flags().BoolVar(&dryRun, "dry-run", false, "Validate and print the request body without sending it")
+	cmd.Flags().BoolVar(&stream, "stream", false,
+		`Read NDJSON signals ({"card_id","signal_type","content"} per line) from stdin and send them as they arrive`)
+	cmd.Flags().IntVar(&batchSize, "batch-size", defaultSignalBatchSize, "Signals per request with --stream")
+	cmd.MarkFlagsMutuallyExclusive("stream", "from-git")
+	cmd.MarkFlagsMutuallyExclusive("attach", "stream")
+	cmd.MarkFlagsMutuallyExclusive("attach", "from-git")
 
 	return cmd
 }
 
+// emitSignal records a single signal against a card, queueing it offline when
+// the API is unreachable.
+func emitSignal(cardID, signalType, content string) (queued bool, err error) {
+	body := signalsBody([]map[string]string{signalEntry(cardID, signalType, content)})
+	_, queued, err = apiPost(api.PathSignals, body)
+	return queued, err
+}
+
+// emitSignalWithAttachment pushes file as an artifact of the signal's card
+// and emits entry referencing it by artifact_hash, plus artifact_id once the
+// server has assigned one. If the push is queued offline the signal is
+// queued as depending on it, so a flush sends the signal only once the
+// artifact is registered and drops it if the artifact is dropped.
+func emitSignalWithAttachment(entry map[string]string, file string) error {
+	token := mustLoadToken()
+	limit, err := artifactSizeLimit("", false)
+	if err != nil {
+		return err
+	}
+	artifact, err := pushArtifact(file, entry["card_id"], token.WorkspaceID, limit, os.Stdout)
+	if err != nil {
+		return fmt.Errorf("failed to attach %s: %w", file, err)
+	}
+	entry["artifact_hash"] = artifact.FileHash
+	if artifact.ArtifactID != "" {
+		entry["artifact_id"] = artifact.ArtifactID
+	}
+
+	body := signalsBody([]map[string]string{entry})
+	var queued bool
+	if artifact.Queued {
+		artifactReq, err := queuedArtifactRequest(artifact)
+		if err != nil {
+			return fmt.Errorf("failed to queue signal: %w", err)
+		}
+		if err := offQueue.AddAfter(artifactReq, "POST", api.PathSignals, body); err != nil {
+			return fmt.Errorf("failed to queue signal: %w", err)
+		}
+		queued = true
+	} else if _, queued, err = apiPost(api.PathSignals, body); err != nil {
+		return fmt.Errorf("failed to emit signal (%s was pushed): %w", artifact.Filename, err)
+	}
+	if queued {
+		fmt.Println("Queued offline. Will flush on next connection.")
+		return nil
+	}
+
+	fmt.Printf("Signal emitted: [%s] on card %s with %s attached\n", entry["signal_type"], entry["card_id"], artifact.Filename)
+	return nil
+}
+
+// queuedArtifactRequest returns the ID of the newest queued registration of
+// artifact.
+func queuedArtifactRequest(artifact pushedArtifact) (string, error) {
+	items, err := offQueue.List()
+	if err != nil {
+		return "", err
+	}
+	for i := len(items) - 1; i >= 0; i-- {
+		item := items[i]
+		if item.Method != "POST" || item.Path != api.PathArtifacts {
+			continue
+		}
+		var body struct {
+			CardID   string `json:"card_id"`
+			FileHash string `json:"file_hash"`
+		}
+		if json.Unmarshal(item.Body, &body) == nil &&
+			body.CardID == artifact.CardID && body.FileHash == artifact.FileHash {
+			return item.ID, nil
+		}
+	}
+	return "", fmt.Errorf("queued artifact %s not found", artifact.Filename)
+}
+
+func signalEntry(cardID, signalType, content string) map[string]string {
+	return map[string]string{
+		"card_id":     cardID,
+		"signal_type": signalType,
+		"content":     content,
+	}
+}
+
+// signalsBody wraps signals the way the API expects: { signals: [...] }.
+func signalsBody(signals []map[string]string) map[string]interface{} {
+	return map[string]interface{}{"signals": signals}
+}
+
+// printSignalsBody prints the request body --dry-run would have POSTed.
+func printSignalsBody(signals []map[string]string) error {
+	out, err := jsonFormatter{}.Format(signalsBody(signals))
+	if err != nil {
+		return err
+	}
+	fmt.Printf("Dry run: would POST %d signal(s) to %s:\n%s", len(signals), api.PathSignals, out)
+	return nil
+}
+
 func isValidSignalType(t string) bool {
 	for _, valid := range validSignalTypes {
 		if t == valid {
@@ -82,3 +253,87 @@ func isValidSignalType(t string) bool {
 	}
 	return false
 }
+
+// gitSignal is a signal found in a commit message.
+type gitSignal struct {
+	commit     string
+	signalType string
+	content    string
+}
+
+// emitGitSignals emits one signal per tagged commit message line in
+// gitRange, for cardID or the current card. With dryRun the signals are
+// printed instead of sent.
+func emitGitSignals(cardID, gitRange string, dryRun bool) error {
+	cardID, err := cardFlagOrCurrent(cardID)
+	if err != nil {
+		return err
+	}
+
+	// %x00 separates hash from body, %x1e ends each commit.
+	out, err := exec.Command("git", "log", "--format=%H%x00%B%x1e", gitRange).Output()
+	if err != nil {
+		if ee, ok := err.(*exec.ExitError); ok && len(ee.Stderr) > 0 {
+			return fmt.Errorf("git log %s: %s", gitRange, strings.TrimSpace(string(ee.Stderr)))
+		}
+		return fmt.Errorf("git log %s: %w", gitRange, err)
+	}
+
+	found := parseGitSignals(string(out))
+	if len(found) == 0 {
+		fmt.Printf("No tagged commit messages in %s.\n", gitRange)
+		return nil
+	}
+
+	signals := make([]map[string]string, 0, len(found))
+	for _, s := range found {
+		signals = append(signals, signalEntry(cardID, s.signalType, s.content))
+	}
+	if dryRun {
+		return printSignalsBody(signals)
+	}
+
+	_, queued, err := apiPost(api.PathSignals, signalsBody(signals))
+	if err != nil {
+		return fmt.Errorf("failed to emit signals: %w", err)
+	}
+	if queued {
+		fmt.Println("Queued offline. Will flush on next connection.")
+		return nil
+	}
+
+	for _, s := range found {
+		fmt.Printf("Signal emitted: [%s] %s (%.7s)\n", s.signalType, s.content, s.commit)
+	}
+	fmt.Printf("%d signal(s) emitted on card %s.\n", len(found), cardID)
+	return nil
+}
+
+// parseGitSignals extracts signals from git log output in the
+// "%H%x00%B%x1e" format. A line tags a signal when it starts with a valid
+// signal type followed by a colon, e.g. "friction: flaky test runner".
+// Commits without such lines contribute nothing.
+func parseGitSignals(log string) []gitSignal {
+	var signals []gitSignal
+	for _, entry := range strings.Split(log, "\x1e") {
+		hash, body, ok := strings.Cut(strings.TrimSpace(entry), "\x00")
+		if !ok {
+			continue
+		}
+
+		scanner := bufio.NewScanner(strings.NewReader(body))
+		for scanner.Scan() {
+			tag, text, ok := strings.Cut(strings.TrimSpace(scanner.Text()), ":")
+			if !ok {
+				continue
+			}
+			tag = strings.ToLower(strings.TrimSpace(tag))
+			text = strings.TrimSpace(text)
+			if text == "" || !isValidSignalType(tag) {
+				continue
+			}
+			signals = append(signals, gitSignal{commit: hash, signalType: tag, content: text})
+		}
+	}
+	return signals
+}
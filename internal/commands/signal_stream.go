@@ -0,0 +1,197 @@
+package commands
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+const (
+	// defaultSignalBatchSize is how many streamed signals go in one POST.
+	defaultSignalBatchSize = 50
+
+	// signalStreamFlushAfter sends a partial batch once the input has been
+	// quiet this long, so slow producers' signals aren't held indefinitely.
+	signalStreamFlushAfter = 2 * time.Second
+
+	// maxSignalLine bounds a single NDJSON line.
+	maxSignalLine = 1 << 20
+)
+
+// streamedSignal is one NDJSON line read by `signal --stream`.
+type streamedSignal struct {
+	CardID     string `json:"card_id"`
+	SignalType string `json:"signal_type"`
+	Content    string `json:"content"`
+}
+
+// streamSignals reads NDJSON signals from r and POSTs them in batches of up
+// to batchSize as they arrive. Lines missing card_id or signal_type use
+// cardID and signalType. Invalid lines are reported and skipped so one bad
+// line doesn't stop a long-running producer. With dryRun each batch body is
+// printed instead of sent.
+func streamSignals(ctx context.Context, r io.Reader, cardID, signalType string, batchSize int, dryRun bool) error {
+	type line struct {
+		n    int
+		text string
+	}
+	lines := make(chan line)
+	readErr := make(chan error, 1)
+	go func() {
+		scanner := bufio.NewScanner(r)
+		scanner.Buffer(make([]byte, 64*1024), maxSignalLine)
+		n := 0
+		for scanner.Scan() {
+			n++
+			select {
+			case lines <- line{n, scanner.Text()}:
+			case <-ctx.Done():
+				return
+			}
+		}
+		readErr <- scanner.Err()
+		close(lines)
+	}()
+
+	var (
+		batch               []map[string]string
+		sent, queued, bad   int
+		flushTimer          = time.NewTimer(signalStreamFlushAfter)
+		flushTimerNeedsStop = true
+	)
+	defer flushTimer.Stop()
+
+	// queueBatch saves the pending batch to the offline queue, for when the
+	// stream is interrupted and requests made on ctx would be aborted.
+	var queueBatch func() error
+
+	flush := func() error {
+		if len(batch) == 0 {
+			return nil
+		}
+		if dryRun {
+			if err := printSignalsBody(batch); err != nil {
+				return err
+			}
+		} else {
+			_, wasQueued, err := apiPost(api.PathSignals, signalsBody(batch))
+			if err != nil && ctx.Err() != nil {
+				return queueBatch()
+			}
+			if err != nil {
+				return fmt.Errorf("failed to emit signals: %w", err)
+			}
+			if wasQueued {
+				queued += len(batch)
+			}
+		}
+		sent += len(batch)
+		logInfo("%d signal(s) sent", sent)
+		batch = batch[:0]
+		return nil
+	}
+
+	queueBatch = func() error {
+		if len(batch) == 0 || dryRun {
+			return flush()
+		}
+		if noQueueFlag {
+			return fmt.Errorf("interrupted: %d signal(s) not sent", len(batch))
+		}
+		if err := offQueue.Add("POST", api.PathSignals, signalsBody(batch)); err != nil {
+			return fmt.Errorf("interrupted: failed to queue %d signal(s): %w", len(batch), err)
+		}
+		logInfo("Interrupted: %d signal(s) queued offline. Will flush on next connection.", len(batch))
+		batch = batch[:0]
+		return nil
+	}
+
+	for {
+		select {
+		case <-ctx.Done():
+			// Don't lose what was already read.
+			return queueBatch()
+
+		case <-flushTimer.C:
+			flushTimerNeedsStop = false
+			if err := flush(); err != nil {
+				return err
+			}
+
+		case l, ok := <-lines:
+			if !ok {
+				if err := flush(); err != nil {
+					return err
+				}
+				if err := <-readErr; err != nil {
+					return fmt.Errorf("reading signals: %w", err)
+				}
+				fmt.Printf("%d signal(s) emitted", sent)
+				if queued > 0 {
+					fmt.Printf(", %d queued offline", queued)
+				}
+				if bad > 0 {
+					fmt.Printf(", %d invalid line(s) skipped", bad)
+				}
+				fmt.Println(".")
+				return nil
+			}
+
+			text := strings.TrimSpace(l.text)
+			if text == "" {
+				continue
+			}
+			s, err := parseStreamedSignal(text, cardID, signalType)
+			if err != nil {
+				logWarn("line %d: %v", l.n, err)
+				bad++
+				continue
+			}
+			batch = append(batch, signalEntry(s.CardID, s.SignalType, s.Content))
+			if len(batch) >= batchSize {
+				if err := flush(); err != nil {
+					return err
+				}
+			}
+
+			// Restart the quiet-period timer after each line.
+			if flushTimerNeedsStop && !flushTimer.Stop() {
+				<-flushTimer.C
+			}
+			flushTimer.Reset(signalStreamFlushAfter)
+			flushTimerNeedsStop = true
+		}
+	}
+}
+
+// parseStreamedSignal decodes and validates one NDJSON line.
+func parseStreamedSignal(text, cardID, signalType string) (streamedSignal, error) {
+	var s streamedSignal
+	if err := json.Unmarshal([]byte(text), &s); err != nil {
+		return s, fmt.Errorf("not a JSON signal object: %w", err)
+	}
+	if s.CardID == "" {
+		s.CardID = cardID
+	}
+	if s.SignalType == "" {
+		s.SignalType = signalType
+	}
+	s.Content = strings.TrimSpace(s.Content)
+
+	switch {
+	case s.CardID == "":
+		return s, fmt.Errorf("missing card_id (or pass --card)")
+	case !isValidSignalType(s.SignalType):
+		return s, fmt.Errorf("invalid signal_type %q. Valid types: %s",
+			s.SignalType, strings.Join(validSignalTypes, ", "))
+	case s.Content == "":
+		return s, fmt.Errorf("missing content")
+	}
+	return s, nil
+}
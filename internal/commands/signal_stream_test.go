@@ -0,0 +1,119 @@
+package commands
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+// queuedSignalCount returns how many signals the queued signal requests
+// hold.
+func queuedSignalCount(t *testing.T) int {
+	t.Helper()
+	items, err := offQueue.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	n := 0
+	for _, item := range items {
+		if item.Path != api.PathSignals {
+			t.Errorf("unexpected queued request %s %s", item.Method, item.Path)
+			continue
+		}
+		var body struct {
+			Signals []map[string]string `json:"signals"`
+		}
+		if err := json.Unmarshal(item.Body, &body); err != nil {
+			t.Fatal(err)
+		}
+		n += len(body.Signals)
+	}
+	return n
+}
+
+// runStream runs streamSignals on ctx in the background, returning a writer
+// for its input and a channel with its result.
+func runStream(ctx context.Context, batchSize int) (*io.PipeWriter, <-chan error) {
+	r, w := io.Pipe()
+	done := make(chan error, 1)
+	go func() {
+		done <- streamSignals(ctx, r, "c1", "friction", batchSize, false)
+	}()
+	return w, done
+}
+
+func writeSignals(t *testing.T, w io.Writer, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		if _, err := io.WriteString(w, `{"content":"slow build"}`+"\n"); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestStreamSignalsQueuesBatchOnCancel(t *testing.T) {
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+	}))
+	defer srv.Close()
+	withOfflineQueue(t, srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	apiClient = api.NewClient(srv.URL, 5, staticToken("tok"), api.WithContext(ctx))
+
+	w, done := runStream(ctx, 50)
+	writeSignals(t, w, 3)
+	// Let the last line reach the batch before interrupting.
+	time.Sleep(100 * time.Millisecond)
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if requests != 0 {
+		t.Errorf("sent %d requests on a cancelled context", requests)
+	}
+	if n := queuedSignalCount(t); n != 3 {
+		t.Errorf("queued %d signals, want 3", n)
+	}
+}
+
+func TestStreamSignalsQueuesAbortedBatch(t *testing.T) {
+	arrived := make(chan struct{}, 1)
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.Copy(io.Discard, r.Body)
+		arrived <- struct{}{}
+		<-release
+	}))
+	defer srv.Close()
+	defer close(release)
+	withOfflineQueue(t, srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	apiClient = api.NewClient(srv.URL, 5, staticToken("tok"), api.WithContext(ctx))
+
+	// A full batch is sent at once; interrupt while it is in flight.
+	w, done := runStream(ctx, 2)
+	writeSignals(t, w, 2)
+	select {
+	case <-arrived:
+	case <-time.After(5 * time.Second):
+		t.Fatal("batch never sent")
+	}
+	cancel()
+
+	if err := <-done; err != nil {
+		t.Fatal(err)
+	}
+	if n := queuedSignalCount(t); n != 2 {
+		t.Errorf("queued %d signals, want 2", n)
+	}
+}
@@ -0,0 +1,97 @@
+package commands
+
+import (
+	"os/exec"
+	"reflect"
+	"testing"
+)
+
+// gitEntry formats one commit as git log --format=%H%x00%B%x1e prints it.
+func gitEntry(hash, body string) string {
+	return hash + "\x00" + body + "\n\x1e\n"
+}
+
+func TestParseGitSignals(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		log  string
+		want []gitSignal
+	}{
+		{"empty", "", nil},
+		{
+			"subject line",
+			gitEntry("aaa", "friction: flaky test runner"),
+			[]gitSignal{{"aaa", "friction", "flaky test runner"}},
+		},
+		{
+			"body lines",
+			gitEntry("aaa", "Fix login\n\nDiscovery: tokens expire early\n  pattern:   retry on 401  \nnot a tag: ignored"),
+			[]gitSignal{
+				{"aaa", "discovery", "tokens expire early"},
+				{"aaa", "pattern", "retry on 401"},
+			},
+		},
+		{
+			"several commits",
+			gitEntry("aaa", "correction: wrong lane name") + gitEntry("bbb", "Refactor only") + gitEntry("ccc", "friction: slow CI"),
+			[]gitSignal{
+				{"aaa", "correction", "wrong lane name"},
+				{"ccc", "friction", "slow CI"},
+			},
+		},
+		{
+			"colon in content",
+			gitEntry("aaa", "discovery: API returns 200 for errors: see #12"),
+			[]gitSignal{{"aaa", "discovery", "API returns 200 for errors: see #12"}},
+		},
+		{"unknown type", gitEntry("aaa", "feat: add picker"), nil},
+		{"empty content", gitEntry("aaa", "friction:   "), nil},
+		{"tag not at line start", gitEntry("aaa", "Note friction: this is prose"), nil},
+		{"missing separator", "aaa friction: no NUL\n\x1e", nil},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got := parseGitSignals(tc.log)
+			if !reflect.DeepEqual(got, tc.want) {
+				t.Errorf("parseGitSignals = %+v, want %+v", got, tc.want)
+			}
+		})
+	}
+}
+
+func TestParseGitSignalsFromGitLog(t *testing.T) {
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("git not installed")
+	}
+	dir := t.TempDir()
+	git := func(args ...string) string {
+		t.Helper()
+		cmd := exec.Command("git", args...)
+		cmd.Dir = dir
+		cmd.Env = append(cmd.Environ(),
+			"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+			"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com")
+		out, err := cmd.Output()
+		if err != nil {
+			t.Fatalf("git %v: %v", args, err)
+		}
+		return string(out)
+	}
+
+	git("init", "-q")
+	git("commit", "-q", "--allow-empty", "-m", "Initial commit")
+	git("commit", "-q", "--allow-empty", "-m", "Speed up board\n\nfriction: board takes 5s to load\npattern: cache the project list")
+
+	found := parseGitSignals(git("log", "--format=%H%x00%B%x1e", "HEAD~1..HEAD"))
+	if len(found) != 2 {
+		t.Fatalf("found %+v, want 2 signals", found)
+	}
+	head := git("rev-parse", "HEAD")
+	for _, s := range found {
+		if s.commit+"\n" != head {
+			t.Errorf("signal %+v attributed to the wrong commit", s)
+		}
+	}
+	if found[0].signalType != "friction" || found[1].content != "cache the project list" {
+		t.Errorf("found %+v", found)
+	}
+}
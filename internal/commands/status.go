@@ -0,0 +1,92 @@
+package commands
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/spf13/cobra"
+)
+
+// statusReport is the structured form of `dea status`.
+type statusReport struct {
+	Endpoint        string     `json:"endpoint"`
+	Auth            authStatus `json:"auth"`
+	CurrentCard     string     `json:"current_card,omitempty"`
+	StagedArtifacts int        `json:"staged_artifacts"`
+	QueuedRequests  int        `json:"queued_requests"`
+	OldestQueuedAt  *time.Time `json:"oldest_queued_at,omitempty"`
+}
+
+func newStatusCommand() *cobra.Command {
+	var out outputOptions
+
+	cmd := &cobra.Command{
+		Use:   "status",
+		Short: "Show local state: login, current card, staged artifacts and queued requests",
+		Long: `Show the local state of this agent without contacting the API: who is
+logged in, the current card, and work not yet sent (staged artifacts in this
+directory and queued offline requests).`,
+		Example: `  # Check what's pending before ending a session
+  dea status
+
+  # Report pending work to an orchestrator
+  dea status --json`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			report := statusReport{
+				Endpoint: cfg.Endpoint,
+				Auth:     authStatusOf(tokenStore.Load()),
+			}
+			if cardID, err := readCurrentCard(); err == nil {
+				report.CurrentCard = cardID
+			}
+			staged, err := loadStagedArtifacts()
+			if err != nil {
+				return fmt.Errorf("failed to load staged artifacts: %w", err)
+			}
+			report.StagedArtifacts = len(staged)
+			items, err := offQueue.List()
+			if err != nil {
+				return fmt.Errorf("failed to load queue: %w", err)
+			}
+			report.QueuedRequests = len(items)
+			for i := range items {
+				if report.OldestQueuedAt == nil || items[i].QueuedAt.Before(*report.OldestQueuedAt) {
+					report.OldestQueuedAt = &items[i].QueuedAt
+				}
+			}
+
+			if ok, err := out.render(report); ok || err != nil {
+				return err
+			}
+
+			fmt.Printf("Endpoint:  %s\n", report.Endpoint)
+			switch a := report.Auth; {
+			case !a.Authenticated:
+				fmt.Println("Auth:      not authenticated")
+			case time.Now().After(*a.ExpiresAt):
+				fmt.Printf("Auth:      %s (workspace %s), token expired %s ago\n",
+					a.AgentID, a.WorkspaceID, formatAge(time.Since(*a.ExpiresAt)))
+			default:
+				fmt.Printf("Auth:      %s (workspace %s), token expires in %s\n",
+					a.AgentID, a.WorkspaceID, formatAge(time.Until(*a.ExpiresAt)))
+			}
+			if report.CurrentCard != "" {
+				fmt.Printf("Card:      %s\n", report.CurrentCard)
+			} else {
+				fmt.Println("Card:      none")
+			}
+			fmt.Printf("Staged:    %d artifact(s)\n", report.StagedArtifacts)
+			if report.OldestQueuedAt != nil {
+				fmt.Printf("Queued:    %d request(s), oldest %s ago\n",
+					report.QueuedRequests, formatAge(time.Since(*report.OldestQueuedAt)))
+			} else {
+				fmt.Println("Queued:    0 requests")
+			}
+			return nil
+		},
+	}
+
+	addOutputFlags(cmd, &out)
+	return cmd
+}
@@ -1,8 +1,8 @@
 package commands
 
 import (
-	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
 	"github.com/spf13/cobra"
@@ -12,30 +12,92 @@ var validStages = []string{
 	"backlog", "ready", "in-progress", "review", "done", "blocked",
 }
 
+// offFlowStages are valid stages outside the forward flow that --to-next
+// follows through validStages.
+var offFlowStages = []string{"blocked"}
+
 func newTransitionCommand() *cobra.Command {
-	return &cobra.Command{
-		Use:   "transition <card-id> <stage>",
+	var (
+		priority string
+		labels   []string
+		comment  string
+		toNext   bool
+	)
+
+	cmd := &cobra.Command{
+		Use:   "transition [card-id] <stage | --to-next>",
 		Short: "Transition a card to a new stage",
-		Long: fmt.Sprintf("Transition a card to a new stage.\nValid stages: %v", validStages),
-		Args: cobra.ExactArgs(2),
+		Long: fmt.Sprintf("Transition a card to a new stage.\nValid stages: %v\n\n"+
+			"When the card ID is omitted on a terminal, pick the card from the board.", validStages),
+		Example: `  # Move a card to review
+  dea transition card-123 review
+
+  # Move the current card and raise its priority
+  dea transition current in-progress --priority high
+
+  # Add labels while transitioning
+  dea transition card-123 blocked --label needs-info --label external
+
+  # Record why the card is moving
+  dea transition card-123 blocked --comment "Waiting on API keys from ops"
+
+  # Advance the current card one stage, e.g. in-progress to review
+  dea transition current --to-next`,
+		Args: cobra.RangeArgs(0, 2),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			cardID := args[0]
-			stage := args[1]
+			switch {
+			case toNext && len(args) > 1:
+				return fmt.Errorf("--to-next takes only a card ID, not a stage")
+			case !toNext && len(args) == 0:
+				return fmt.Errorf("a stage is required (or pass --to-next)")
+			}
 			mustLoadToken()
 
-			// Normalize stage name: CLI uses "in-progress" but DB uses "in_progress"
-			lane := stage
-			if lane == "in-progress" {
-				lane = "in_progress"
+			var (
+				stage  string
+				cardID string
+				err    error
+			)
+			if toNext {
+				if cardID, err = cardIDArg(args, nil); err != nil {
+					return err
+				}
+				if stage, err = nextCardStage(cardID); err != nil {
+					return err
+				}
+				fmt.Printf("Moving card %s to %s.\n", cardID, stage)
+			} else {
+				stage = args[len(args)-1]
+				// With the card ID omitted, a mistyped "transition card-123"
+				// would otherwise move a picked card to lane "card_123".
+				if len(args) == 1 && !isKnownStage(stage) {
+					return fmt.Errorf("unknown stage %q (did you forget the stage?). Valid stages: %s",
+						stage, strings.Join(validStages, ", "))
+				}
+				if cardID, err = cardIDArg(args[:len(args)-1], nil); err != nil {
+					return err
+				}
 			}
 
-			body := map[string]string{
+			// CLI lane names may differ from the DB ones ("in-progress" vs "in_progress").
+			lane := toDBLane(stage)
+
+			body := map[string]interface{}{
 				"target_lane": lane,
 			}
+			if priority != "" {
+				body["priority"] = priority
+			}
+			if len(labels) > 0 {
+				body["labels"] = labels
+			}
+			if comment = strings.TrimSpace(comment); comment != "" {
+				body["comment"] = comment
+			}
 
 			data, err := apiClient.Post(api.CardTransitionPath(cardID), body)
 			if err != nil {
-				if isNetworkErr(err) {
+				if api.IsNetworkError(err) {
 					return err
 				}
 				// Check if it looks like a governance rejection.
@@ -48,20 +110,69 @@ func newTransitionCommand() *cobra.Command {
 			}
 
 			// Parse response.
-			var resp map[string]interface{}
-			if len(data) > 0 {
-				if err := json.Unmarshal(data, &resp); err == nil {
-					if msg, ok := resp["message"].(string); ok {
-						fmt.Println(msg)
-						return nil
-					}
+			msg := fmt.Sprintf("Card %s transitioned to %s.", cardID, stage)
+			if resp, err := unwrapObject(data); err == nil {
+				if m := strField(resp, "message", ""); m != "" {
+					msg = m
 				}
 			}
+			fmt.Println(msg)
 
-			fmt.Printf("Card %s transitioned to %s.\n", cardID, stage)
+			if cfg.SignalOnTransition {
+				recordTransitionSignal(cardID, lane, comment)
+			}
 			return nil
 		},
 	}
+
+	cmd.Flags().StringVar(&priority, "priority", "", "Also set the card's priority")
+	cmd.Flags().StringSliceVar(&labels, "label", nil, "Also add a label (repeatable)")
+	cmd.Flags().StringVar(&comment, "comment", "", "Reason for the transition, stored with it for audit")
+	cmd.Flags().BoolVar(&toNext, "to-next", false,
+		"Advance the card to the next stage in the order "+strings.Join(forwardStages(), " -> "))
+	return cmd
+}
+
+// forwardStages is the stage order --to-next follows: validStages without
+// the off-flow ones.
+func forwardStages() []string {
+	var stages []string
+	for _, s := range validStages {
+		if !containsString(offFlowStages, s) {
+			stages = append(stages, s)
+		}
+	}
+	return stages
+}
+
+// nextCardStage looks up the card's lane and returns the stage after it in
+// forwardStages.
+func nextCardStage(cardID string) (string, error) {
+	data, err := apiClient.Get(api.CardContextPath(cardID))
+	if err != nil {
+		return "", handleAPIError(err, "card", cardID, "get")
+	}
+	card, err := extractCard(data)
+	if err != nil {
+		return "", fmt.Errorf("invalid context for card %s: %w", cardID, err)
+	}
+	lane := strField(card, "lane", strField(card, "status", ""))
+	if lane == "" {
+		return "", fmt.Errorf("card %s has no lane", cardID)
+	}
+
+	stages := forwardStages()
+	for i, s := range stages {
+		if !sameLane(s, lane) {
+			continue
+		}
+		if i == len(stages)-1 {
+			return "", fmt.Errorf("card %s is already in %s, the last stage", cardID, s)
+		}
+		return stages[i+1], nil
+	}
+	return "", fmt.Errorf("card %s is in %s, which has no next stage; name the target stage instead",
+		cardID, toDisplayLane(lane))
 }
 
 func isGovernanceRejection(errMsg string) bool {
@@ -72,3 +183,26 @@ func isGovernanceRejection(errMsg string) bool {
 	}
 	return false
 }
+
+// recordTransitionSignal emits the audit signal enabled by
+// signal_on_transition. The transition has already happened, so failures
+// are only warned about.
+func recordTransitionSignal(cardID, lane, comment string) {
+	signalType := cfg.SignalOnTransitionType
+	if !isValidSignalType(signalType) {
+		logWarn("not recording transition: invalid signal_on_transition_type %q", signalType)
+		return
+	}
+
+	content := fmt.Sprintf("Card %s transitioned to %s", cardID, toDisplayLane(lane))
+	if comment != "" {
+		content += ": " + comment
+	}
+	queued, err := emitSignal(cardID, signalType, content)
+	switch {
+	case err != nil:
+		logWarn("failed to record transition signal: %v", err)
+	case queued:
+		fmt.Println("Queued transition signal offline. Will flush on next connection.")
+	}
+}
@@ -0,0 +1,74 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// unwrapData decodes an API response and strips the Edge Function envelope:
+// a top-level { data: ... } is unwrapped, then each key is descended into
+// while present. So unwrapData(raw, "card") yields the card for
+// {data:{card:{...}}}, {data:{...}} and a bare {...} alike.
+func unwrapData(raw []byte, keys ...string) (interface{}, error) {
+	var v interface{}
+	if err := json.Unmarshal(raw, &v); err != nil {
+		return nil, err
+	}
+
+	if obj, ok := v.(map[string]interface{}); ok {
+		if d, ok := obj["data"]; ok {
+			v = d
+		}
+	}
+
+	for _, key := range keys {
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			break
+		}
+		next, ok := obj[key]
+		if !ok {
+			break
+		}
+		v = next
+	}
+	return v, nil
+}
+
+// unwrapObject is unwrapData for responses that must hold a JSON object.
+func unwrapObject(raw []byte, keys ...string) (map[string]interface{}, error) {
+	v, err := unwrapData(raw, keys...)
+	if err != nil {
+		return nil, err
+	}
+	obj, ok := v.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON object, got %T", v)
+	}
+	return obj, nil
+}
+
+// unwrapList is unwrapData for responses that must hold an array of objects.
+// Non-object elements are dropped, and a null list ({data:null},
+// {data:{cards:null}}) is an empty one.
+func unwrapList(raw []byte, keys ...string) ([]map[string]interface{}, error) {
+	v, err := unwrapData(raw, keys...)
+	if err != nil {
+		return nil, err
+	}
+	if v == nil {
+		return []map[string]interface{}{}, nil
+	}
+	arr, ok := v.([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("expected a JSON array, got %T", v)
+	}
+
+	items := make([]map[string]interface{}, 0, len(arr))
+	for _, item := range arr {
+		if obj, ok := item.(map[string]interface{}); ok {
+			items = append(items, obj)
+		}
+	}
+	return items, nil
+}
@@ -0,0 +1,121 @@
+package commands
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+type staticToken string
+
+func (t staticToken) GetToken() string { return string(t) }
+
+func TestUnwrapData(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		raw  string
+		keys []string
+		want string
+	}{
+		{"bare object", `{"id":"c1"}`, []string{"card"}, `{"id":"c1"}`},
+		{"data envelope", `{"data":{"id":"c1"}}`, []string{"card"}, `{"id":"c1"}`},
+		{"data and key", `{"data":{"card":{"id":"c1"}}}`, []string{"card"}, `{"id":"c1"}`},
+		{"key without data", `{"card":{"id":"c1"}}`, []string{"card"}, `{"id":"c1"}`},
+		{"nested keys", `{"data":{"card":{"history":[1]}}}`, []string{"card", "history"}, `[1]`},
+		{"stops at missing key", `{"data":{"history":[1]}}`, []string{"card", "history"}, `{"history":[1]}`},
+		{"bare array", `[1,2]`, []string{"cards"}, `[1,2]`},
+		{"null data", `{"data":null}`, []string{"cards"}, `null`},
+		{"null list", `{"data":{"cards":null}}`, []string{"cards"}, `null`},
+		{"no keys", `{"data":{"card":{}}}`, nil, `{"card":{}}`},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := unwrapData([]byte(tc.raw), tc.keys...)
+			if err != nil {
+				t.Fatal(err)
+			}
+			var want interface{}
+			if err := json.Unmarshal([]byte(tc.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("unwrapData(%s) = %v, want %v", tc.raw, got, want)
+			}
+		})
+	}
+
+	if _, err := unwrapData([]byte(`<html>`)); err == nil {
+		t.Error("expected an error for a non-JSON body")
+	}
+}
+
+func TestUnwrapObject(t *testing.T) {
+	obj, err := unwrapObject([]byte(`{"data":{"card":{"id":"c1"}}}`), "card")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if obj["id"] != "c1" {
+		t.Errorf("got %v, want card c1", obj)
+	}
+	if _, err := unwrapObject([]byte(`{"data":[1]}`), "card"); err == nil {
+		t.Error("expected an error for an array")
+	}
+}
+
+func TestUnwrapList(t *testing.T) {
+	for _, tc := range []struct {
+		name string
+		raw  string
+		want int
+	}{
+		{"bare array", `[{"id":"a"},{"id":"b"}]`, 2},
+		{"data array", `{"data":[{"id":"a"}]}`, 1},
+		{"data cards", `{"data":{"cards":[{"id":"a"},{"id":"b"}]}}`, 2},
+		{"non-objects dropped", `{"data":[{"id":"a"},"b",3,null]}`, 1},
+		{"empty", `{"data":{"cards":[]}}`, 0},
+		{"null data", `{"data":null}`, 0},
+		{"null cards", `{"data":{"cards":null}}`, 0},
+		{"null body", `null`, 0},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			items, err := unwrapList([]byte(tc.raw), "cards")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if items == nil || len(items) != tc.want {
+				t.Errorf("unwrapList(%s) = %v, want %d items", tc.raw, items, tc.want)
+			}
+		})
+	}
+
+	for _, raw := range []string{`{"data":{"cards":{"id":"a"}}}`, `{"data":"none"}`} {
+		if _, err := unwrapList([]byte(raw), "cards"); err == nil {
+			t.Errorf("unwrapList(%s): expected an error", raw)
+		}
+	}
+}
+
+func TestFetchBoardNullCards(t *testing.T) {
+	for _, body := range []string{`{"data":null}`, `{"data":{"cards":null}}`} {
+		srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.Header().Set("Content-Type", "application/json")
+			w.Write([]byte(body))
+		}))
+		old := apiClient
+		apiClient = api.NewClient(srv.URL, 5, staticToken("tok"))
+
+		cards, err := fetchBoard("p1")
+		apiClient = old
+		srv.Close()
+
+		if err != nil {
+			t.Errorf("%s: %v", body, err)
+		}
+		if len(cards) != 0 {
+			t.Errorf("%s: got %d cards, want none", body, len(cards))
+		}
+	}
+}
@@ -5,17 +5,25 @@ import (
 	"archive/zip"
 	"bytes"
 	"compress/gzip"
+	"context"
 	"crypto/sha256"
+	"debug/elf"
+	"debug/macho"
+	"debug/pe"
 	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
 	"os"
+	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
 	"strings"
+	"time"
 
+	"github.com/dea-exmachina/dea-cli/internal/config"
 	"github.com/spf13/cobra"
 )
 
@@ -35,20 +43,46 @@ type releaseAsset struct {
 	BrowserDownloadURL string `json:"browser_download_url"`
 }
 
+// exitAlreadyLatest is the exit code `dea update` uses when no update was
+// needed, so scripts can tell it apart from a successful update (0).
+const exitAlreadyLatest = 10
+
+// updateResult is the --json output of `dea update`.
+type updateResult struct {
+	Current string `json:"current"`
+	Latest  string `json:"latest"`
+	Updated bool   `json:"updated"`
+}
+
 // newUpdateCommand returns the `dea update` cobra command.
 func newUpdateCommand(currentVersion, currentCommit, currentDate string) *cobra.Command {
-	return &cobra.Command{
+	var jsonOut, skipExecCheck, preferBinary bool
+
+	cmd := &cobra.Command{
 		Use:   "update",
 		Short: "Update dea to the latest version",
-		Long:  "Checks GitHub Releases for a newer version and replaces the running binary.",
-		RunE:  runUpdate(currentVersion),
+		Long: `Checks GitHub Releases for a newer version and replaces the running binary.
+
+Exit codes: 0 updated, 10 already at the latest version, 1 error.`,
+		RunE: runUpdate(currentVersion, &jsonOut, &skipExecCheck, &preferBinary),
 	}
+
+	cmd.Flags().BoolVar(&jsonOut, "json", false, "Print {current, latest, updated} as JSON (progress goes to stderr)")
+	cmd.Flags().BoolVar(&preferBinary, "prefer-binary-asset", false, "Prefer a bare binary release asset over the archive when both exist")
+	cmd.Flags().BoolVar(&skipExecCheck, "skip-exec-check", false, "Do not run the new binary with --version before installing it")
+	return cmd
 }
 
-func runUpdate(currentVersion string) func(cmd *cobra.Command, args []string) error {
+func runUpdate(currentVersion string, jsonOut, skipExecCheck, preferBinary *bool) func(cmd *cobra.Command, args []string) error {
 	return func(cmd *cobra.Command, args []string) error {
-		fmt.Printf("Current version: %s\n", currentVersion)
-		fmt.Println("Checking for updates...")
+		// Keep stdout clean for the JSON result.
+		var out io.Writer = os.Stdout
+		if *jsonOut {
+			out = &logWriter{}
+		}
+
+		fmt.Fprintf(out, "Current version: %s\n", currentVersion)
+		fmt.Fprintln(out, "Checking for updates...")
 
 		// 1. GET latest release from GitHub API.
 		release, err := fetchLatestRelease()
@@ -59,25 +93,37 @@ func runUpdate(currentVersion string) func(cmd *cobra.Command, args []string) er
 		latestVersion := strings.TrimPrefix(release.TagName, "v")
 		currentClean := strings.TrimPrefix(currentVersion, "v")
 
+		result := updateResult{Current: currentVersion, Latest: release.TagName}
+
 		// 2. Compare versions.
 		if latestVersion == currentClean || currentClean == "dev" && latestVersion == "" {
-			fmt.Printf("Already at latest version: %s\n", currentVersion)
-			return nil
-		}
-		if latestVersion == currentClean {
-			fmt.Printf("Already at latest version: %s\n", currentVersion)
-			return nil
+			fmt.Fprintf(out, "Already at latest version: %s\n", currentVersion)
+			if err := printUpdateResult(result, *jsonOut); err != nil {
+				return err
+			}
+			return &exitError{code: exitAlreadyLatest}
 		}
 
-		fmt.Printf("New version available: %s -> %s\n", currentVersion, release.TagName)
+		fmt.Fprintf(out, "New version available: %s -> %s\n", currentVersion, release.TagName)
 
-		// 3. Find asset for current GOOS/GOARCH.
-		assetName := buildAssetName(release.TagName)
+		// 3. Find asset for current GOOS/GOARCH: the archive by default, or a
+		// bare binary for releases that publish one.
+		candidates := append([]string{buildAssetName(release.TagName)}, rawAssetNames(release.TagName)...)
+		if *preferBinary {
+			candidates = append(candidates[1:], candidates[0])
+		}
 		checksumAssetName := "checksums.txt"
 
-		assetURL := findAssetURL(release.Assets, assetName)
+		var assetName, assetURL string
+		for _, name := range candidates {
+			if assetURL = findAssetURL(release.Assets, name); assetURL != "" {
+				assetName = name
+				break
+			}
+		}
 		if assetURL == "" {
-			return fmt.Errorf("no asset found for %s/%s (looking for %s)", runtime.GOOS, runtime.GOARCH, assetName)
+			return fmt.Errorf("no asset found for %s/%s (looking for %s)",
+				runtime.GOOS, runtime.GOARCH, strings.Join(candidates, ", "))
 		}
 
 		checksumURL := findAssetURL(release.Assets, checksumAssetName)
@@ -86,28 +132,35 @@ func runUpdate(currentVersion string) func(cmd *cobra.Command, args []string) er
 		}
 
 		// 4. Download the asset.
-		fmt.Printf("Downloading %s...\n", assetName)
-		assetData, err := downloadBytes(assetURL)
+		fmt.Fprintf(out, "Downloading %s...\n", assetName)
+		partPath := filepath.Join(config.DeaDir(), "cache", release.TagName+"-"+assetName+".part")
+		assetData, err := downloadResumable(assetURL, partPath)
 		if err != nil {
 			return fmt.Errorf("download failed: %w", err)
 		}
 
 		// 5. Verify SHA256 against checksums.txt.
-		fmt.Println("Verifying checksum...")
-		checksumData, err := downloadBytes(checksumURL)
+		fmt.Fprintln(out, "Verifying checksum...")
+		checksumData, err := fetchChecksums(release.TagName, checksumURL)
 		if err != nil {
 			return fmt.Errorf("failed to download checksums: %w", err)
 		}
+		// The partial download is only useful until the bytes are verified;
+		// a corrupt one must not be resumed next time either.
+		os.Remove(partPath)
 		if err := verifyChecksum(assetData, checksumData, assetName); err != nil {
 			return fmt.Errorf("checksum verification failed: %w", err)
 		}
-		fmt.Println("Checksum OK.")
+		fmt.Fprintln(out, "Checksum OK.")
 
 		// 6. Extract binary from archive.
 		binaryData, err := extractBinary(assetData, assetName)
 		if err != nil {
 			return fmt.Errorf("failed to extract binary: %w", err)
 		}
+		if err := verifyBinary(binaryData); err != nil {
+			return fmt.Errorf("downloaded binary failed verification: %w", err)
+		}
 
 		// 7. Write to temp file alongside current executable.
 		execPath, err := os.Executable()
@@ -119,20 +172,68 @@ func runUpdate(currentVersion string) func(cmd *cobra.Command, args []string) er
 			return fmt.Errorf("could not resolve symlinks: %w", err)
 		}
 
+		// Carry the current binary's mode (and owner, on Unix) over to the
+		// replacement rather than a fixed 0755.
+		execInfo, err := os.Stat(execPath)
+		if err != nil {
+			return fmt.Errorf("could not stat current binary: %w", err)
+		}
+
 		tmpPath := execPath + ".new"
-		if err := os.WriteFile(tmpPath, binaryData, 0755); err != nil {
+		if err := os.WriteFile(tmpPath, binaryData, execInfo.Mode().Perm()); err != nil {
 			return fmt.Errorf("failed to write new binary: %w", err)
 		}
+		if err := os.Chmod(tmpPath, execInfo.Mode().Perm()); err != nil {
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("failed to set mode on new binary: %w", err)
+		}
+		if err := matchOwner(tmpPath, execInfo); err != nil {
+			logWarn("could not preserve binary owner: %v", err)
+		}
+
+		if !*skipExecCheck {
+			if err := checkBinaryRuns(tmpPath); err != nil {
+				_ = os.Remove(tmpPath)
+				return fmt.Errorf("new binary failed to run: %w", err)
+			}
+		}
 
-		// 8. Atomic replace (rename is atomic on the same filesystem).
-		if err := os.Rename(tmpPath, execPath); err != nil {
+		// 8. Swap the new binary into place.
+		if err := replaceBinary(tmpPath, execPath); err != nil {
 			_ = os.Remove(tmpPath)
 			return fmt.Errorf("failed to replace binary: %w", err)
 		}
 
-		fmt.Printf("Updated to %s. Run `dea --version` to confirm.\n", release.TagName)
+		fmt.Fprintf(out, "Updated to %s. Run `dea --version` to confirm.\n", release.TagName)
+		result.Updated = true
+		return printUpdateResult(result, *jsonOut)
+	}
+}
+
+// printUpdateResult writes the --json summary to stdout when requested.
+func printUpdateResult(result updateResult, jsonOut bool) error {
+	if !jsonOut {
 		return nil
 	}
+	data, err := json.Marshal(result)
+	if err != nil {
+		return err
+	}
+	fmt.Println(string(data))
+	return nil
+}
+
+// cleanupOldBinary removes the <exe>.old left behind by a Windows update,
+// which cannot be deleted while the previous process is still running.
+func cleanupOldBinary() {
+	execPath, err := os.Executable()
+	if err != nil {
+		return
+	}
+	if execPath, err = filepath.EvalSymlinks(execPath); err != nil {
+		return
+	}
+	_ = os.Remove(execPath + ".old")
 }
 
 // fetchLatestRelease calls the GitHub API for the latest release.
@@ -169,6 +270,21 @@ func buildAssetName(tag string) string {
 	return fmt.Sprintf("dea_%s_%s_%s.%s", ver, goos, goarch, ext)
 }
 
+// rawAssetNames returns the names a release may use for a bare (unarchived)
+// binary: dea_<version>_<os>_<arch> and dea_<os>_<arch>, with .exe on Windows.
+func rawAssetNames(tag string) []string {
+	ver := strings.TrimPrefix(tag, "v")
+	ext := ""
+	if runtime.GOOS == "windows" {
+		ext = ".exe"
+	}
+
+	return []string{
+		fmt.Sprintf("dea_%s_%s_%s%s", ver, runtime.GOOS, runtime.GOARCH, ext),
+		fmt.Sprintf("dea_%s_%s%s", runtime.GOOS, runtime.GOARCH, ext),
+	}
+}
+
 // findAssetURL searches release assets for a matching name.
 func findAssetURL(assets []releaseAsset, name string) string {
 	for _, a := range assets {
@@ -193,25 +309,225 @@ func downloadBytes(url string) ([]byte, error) {
 	return io.ReadAll(resp.Body)
 }
 
+const (
+	// downloadAttempts is how many times downloadResumable tries before
+	// giving up, resuming from what it has each time.
+	downloadAttempts = 4
+
+	// downloadRetryDelay is the wait before the first retry; it doubles
+	// after each.
+	downloadRetryDelay = time.Second
+)
+
+// downloadResumable downloads url into partPath and returns the complete
+// body. Bytes already in partPath, e.g. from an interrupted earlier run, are
+// kept and only the rest is requested with a Range header; servers that
+// ignore Range get a fresh download. Failed attempts are retried the same
+// way. The caller removes partPath once it has verified the result.
+func downloadResumable(url, partPath string) ([]byte, error) {
+	if err := os.MkdirAll(filepath.Dir(partPath), 0700); err != nil {
+		return nil, err
+	}
+
+	delay := downloadRetryDelay
+	for attempt := 1; ; attempt++ {
+		err := downloadPart(url, partPath)
+		if err == nil {
+			return os.ReadFile(partPath)
+		}
+		if attempt >= downloadAttempts {
+			return nil, err
+		}
+		logWarn("%v; retrying in %s...", err, delay)
+		time.Sleep(delay)
+		delay *= 2
+	}
+}
+
+// downloadPart appends the rest of url to partPath.
+func downloadPart(url, partPath string) error {
+	var have int64
+	if info, err := os.Stat(partPath); err == nil {
+		have = info.Size()
+	}
+
+	req, err := http.NewRequest(http.MethodGet, url, nil) //nolint:noctx
+	if err != nil {
+		return err
+	}
+	if have > 0 {
+		req.Header.Set("Range", fmt.Sprintf("bytes=%d-", have))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	flags := os.O_CREATE | os.O_WRONLY
+	switch resp.StatusCode {
+	case http.StatusPartialContent:
+		flags |= os.O_APPEND
+	case http.StatusOK:
+		// Range ignored (or nothing to resume): start over.
+		flags |= os.O_TRUNC
+	case http.StatusRequestedRangeNotSatisfiable:
+		// Nothing left to fetch: an earlier run got the whole asset but
+		// stopped before verifying it. The checksum decides if it's good.
+		if have > 0 {
+			return nil
+		}
+		return fmt.Errorf("HTTP %d for %s", resp.StatusCode, url)
+	default:
+		return fmt.Errorf("HTTP %d for %s", resp.StatusCode, url)
+	}
+
+	f, err := os.OpenFile(partPath, flags, 0600)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(f, resp.Body); err != nil {
+		f.Close()
+		return fmt.Errorf("download interrupted: %w", err)
+	}
+	return f.Close()
+}
+
+// fetchChecksums returns checksums.txt for a release, reusing a copy cached
+// under DeaDir/cache since a published release's checksums never change.
+func fetchChecksums(tag, url string) ([]byte, error) {
+	cachePath := filepath.Join(config.DeaDir(), "cache", "checksums-"+tag+".txt")
+	if data, err := os.ReadFile(cachePath); err == nil && len(data) > 0 {
+		return data, nil
+	}
+
+	data, err := downloadBytes(url)
+	if err != nil {
+		return nil, err
+	}
+
+	// Caching is best-effort; a failure here must not block the update.
+	if err := os.MkdirAll(filepath.Dir(cachePath), 0700); err == nil {
+		_ = os.WriteFile(cachePath, data, 0600)
+	}
+	return data, nil
+}
+
 // verifyChecksum checks the SHA256 of data against the checksums file.
 func verifyChecksum(data, checksumFile []byte, assetName string) error {
 	h := sha256.Sum256(data)
 	actualHash := hex.EncodeToString(h[:])
 
-	// Parse checksums.txt (format: "<hash>  <filename>")
+	expected, ok := lookupChecksum(checksumFile, assetName)
+	if !ok {
+		return fmt.Errorf("no checksum entry found for %s", assetName)
+	}
+	if !strings.EqualFold(expected, actualHash) {
+		return fmt.Errorf("expected %s, got %s", expected, actualHash)
+	}
+	return nil
+}
+
+// lookupChecksum finds the hash for name in a sha256sum-style file. Both the
+// text layout ("<hash>  <name>") and the binary-marker layout
+// ("<hash> *<name>") are accepted.
+func lookupChecksum(checksumFile []byte, name string) (string, bool) {
 	for _, line := range strings.Split(string(checksumFile), "\n") {
-		parts := strings.Fields(line)
-		if len(parts) == 2 && parts[1] == assetName {
-			if parts[0] != actualHash {
-				return fmt.Errorf("expected %s, got %s", parts[0], actualHash)
+		fields := strings.Fields(line)
+		if len(fields) < 2 {
+			continue
+		}
+		file := strings.TrimPrefix(strings.Join(fields[1:], " "), "*")
+		if strings.TrimSpace(file) == name {
+			return fields[0], true
+		}
+	}
+	return "", false
+}
+
+// verifyBinary checks that data is an executable in the native format for the
+// running GOOS, built for the running GOARCH.
+func verifyBinary(data []byte) error {
+	r := bytes.NewReader(data)
+
+	switch runtime.GOOS {
+	case "windows":
+		f, err := pe.NewFile(r)
+		if err != nil {
+			return fmt.Errorf("not a PE executable: %w", err)
+		}
+		want, known := map[string]uint16{
+			"386":   pe.IMAGE_FILE_MACHINE_I386,
+			"amd64": pe.IMAGE_FILE_MACHINE_AMD64,
+			"arm64": pe.IMAGE_FILE_MACHINE_ARM64,
+		}[runtime.GOARCH]
+		if known && f.Machine != want {
+			return fmt.Errorf("PE machine %#x does not match %s", f.Machine, runtime.GOARCH)
+		}
+
+	case "darwin":
+		want, known := map[string]macho.Cpu{
+			"amd64": macho.CpuAmd64,
+			"arm64": macho.CpuArm64,
+		}[runtime.GOARCH]
+
+		if fat, err := macho.NewFatFile(r); err == nil {
+			if !known {
+				return nil
 			}
-			return nil
+			for _, arch := range fat.Arches {
+				if arch.Cpu == want {
+					return nil
+				}
+			}
+			return fmt.Errorf("universal binary has no %s slice", runtime.GOARCH)
+		}
+
+		f, err := macho.NewFile(r)
+		if err != nil {
+			return fmt.Errorf("not a Mach-O executable: %w", err)
+		}
+		if known && f.Cpu != want {
+			return fmt.Errorf("Mach-O cpu %v does not match %s", f.Cpu, runtime.GOARCH)
+		}
+
+	default:
+		f, err := elf.NewFile(r)
+		if err != nil {
+			return fmt.Errorf("not an ELF executable: %w", err)
+		}
+		want, known := map[string]elf.Machine{
+			"386":   elf.EM_386,
+			"amd64": elf.EM_X86_64,
+			"arm":   elf.EM_ARM,
+			"arm64": elf.EM_AARCH64,
+		}[runtime.GOARCH]
+		if known && f.Machine != want {
+			return fmt.Errorf("ELF machine %v does not match %s", f.Machine, runtime.GOARCH)
 		}
 	}
-	return fmt.Errorf("no checksum entry found for %s", assetName)
+	return nil
 }
 
-// extractBinary extracts the `dea` or `dea.exe` binary from a tar.gz or zip archive.
+// checkBinaryRuns executes `<path> --version` and checks that it reports a
+// version, catching binaries that pass header checks but cannot start.
+func checkBinaryRuns(path string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	output, err := exec.CommandContext(ctx, path, "--version").CombinedOutput()
+	if err != nil {
+		return fmt.Errorf("%s --version: %w", filepath.Base(path), err)
+	}
+	if !strings.Contains(string(output), "version") {
+		return fmt.Errorf("unexpected --version output: %q", strings.TrimSpace(string(output)))
+	}
+	return nil
+}
+
+// extractBinary extracts the `dea` or `dea.exe` binary from a tar.gz or zip
+// archive. Any other asset is taken to be the bare binary and returned as-is;
+// verifyBinary then confirms it really is an executable.
 func extractBinary(archiveData []byte, assetName string) ([]byte, error) {
 	binaryName := "dea"
 	if runtime.GOOS == "windows" {
@@ -224,10 +540,12 @@ func extractBinary(archiveData []byte, assetName string) ([]byte, error) {
 	if strings.HasSuffix(assetName, ".zip") {
 		return extractFromZip(archiveData, binaryName)
 	}
-	return nil, fmt.Errorf("unsupported archive format: %s", assetName)
+	return archiveData, nil
 }
 
-// extractFromTarGz extracts a named file from a tar.gz archive.
+// extractFromTarGz extracts a named file from a tar.gz archive. When several
+// entries share the name, the one closest to the archive root wins. Archives
+// containing entries that escape the root are rejected outright.
 func extractFromTarGz(data []byte, name string) ([]byte, error) {
 	gr, err := gzip.NewReader(bytes.NewReader(data))
 	if err != nil {
@@ -235,6 +553,9 @@ func extractFromTarGz(data []byte, name string) ([]byte, error) {
 	}
 	defer gr.Close()
 
+	var found []byte
+	bestDepth := -1
+
 	tr := tar.NewReader(gr)
 	for {
 		hdr, err := tr.Next()
@@ -245,30 +566,75 @@ func extractFromTarGz(data []byte, name string) ([]byte, error) {
 			return nil, fmt.Errorf("tar read error: %w", err)
 		}
 
-		// Match on the base name to handle directories in the archive.
-		if filepath.Base(hdr.Name) == name {
-			return io.ReadAll(tr)
+		depth, err := archiveEntryDepth(hdr.Name)
+		if err != nil {
+			return nil, err
 		}
+		if hdr.Typeflag != tar.TypeReg || path.Base(hdr.Name) != name {
+			continue
+		}
+		if bestDepth == -1 || depth < bestDepth {
+			if found, err = io.ReadAll(tr); err != nil {
+				return nil, fmt.Errorf("tar read error: %w", err)
+			}
+			bestDepth = depth
+		}
+	}
+
+	if bestDepth == -1 {
+		return nil, fmt.Errorf("binary %q not found in archive", name)
 	}
-	return nil, fmt.Errorf("binary %q not found in archive", name)
+	return found, nil
 }
 
-// extractFromZip extracts a named file from a zip archive.
+// extractFromZip extracts a named file from a zip archive, with the same
+// top-level preference and traversal guard as extractFromTarGz.
 func extractFromZip(data []byte, name string) ([]byte, error) {
 	r, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
 	if err != nil {
 		return nil, fmt.Errorf("failed to open zip: %w", err)
 	}
 
+	var best *zip.File
+	bestDepth := -1
 	for _, f := range r.File {
-		if filepath.Base(f.Name) == name {
-			rc, err := f.Open()
-			if err != nil {
-				return nil, fmt.Errorf("failed to open zip entry: %w", err)
-			}
-			defer rc.Close()
-			return io.ReadAll(rc)
+		depth, err := archiveEntryDepth(f.Name)
+		if err != nil {
+			return nil, err
+		}
+		if f.FileInfo().IsDir() || path.Base(f.Name) != name {
+			continue
 		}
+		if bestDepth == -1 || depth < bestDepth {
+			best, bestDepth = f, depth
+		}
+	}
+
+	if best == nil {
+		return nil, fmt.Errorf("binary %q not found in archive", name)
+	}
+
+	rc, err := best.Open()
+	if err != nil {
+		return nil, fmt.Errorf("failed to open zip entry: %w", err)
+	}
+	defer rc.Close()
+	return io.ReadAll(rc)
+}
+
+// archiveEntryDepth returns how many directories deep an archive entry sits,
+// rejecting absolute names and names that climb out of the archive root
+// (e.g. "../../dea"). Backslashes are treated as separators so Windows-style
+// names cannot slip past the check.
+func archiveEntryDepth(name string) (int, error) {
+	slashed := strings.ReplaceAll(name, "\\", "/")
+	clean := path.Clean(slashed)
+	if path.IsAbs(clean) || clean == ".." || strings.HasPrefix(clean, "../") ||
+		(len(slashed) >= 2 && slashed[1] == ':') {
+		return 0, fmt.Errorf("archive entry %q escapes the archive root", name)
+	}
+	if clean == "." {
+		return 0, nil
 	}
-	return nil, fmt.Errorf("binary %q not found in archive", name)
+	return strings.Count(clean, "/"), nil
 }
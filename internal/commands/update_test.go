@@ -0,0 +1,215 @@
+package commands
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/http/httptest"
+	"runtime"
+	"strings"
+	"testing"
+)
+
+func sha256Hex(data []byte) string {
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:])
+}
+
+func TestLookupChecksum(t *testing.T) {
+	const hash = "0123456789abcdef0123456789abcdef0123456789abcdef0123456789abcdef"
+
+	for _, tc := range []struct {
+		name string
+		file string
+		want bool
+	}{
+		{"text layout", hash + "  dea_1.2.0_linux_amd64.tar.gz\n", true},
+		{"binary marker", hash + " *dea_1.2.0_linux_amd64.tar.gz\n", true},
+		{"single space", hash + " dea_1.2.0_linux_amd64.tar.gz", true},
+		{"tab separated", hash + "\tdea_1.2.0_linux_amd64.tar.gz", true},
+		{"CRLF", hash + "  dea_1.2.0_linux_amd64.tar.gz\r\n", true},
+		{"among others", "ffff  checksums.txt\n" + hash + " *dea_1.2.0_linux_amd64.tar.gz\nffff  dea_1.2.0_darwin_arm64.tar.gz\n", true},
+		{"other asset only", hash + "  dea_1.2.0_darwin_arm64.tar.gz\n", false},
+		{"prefix of name", hash + "  dea_1.2.0_linux_amd64.tar.gz.sig\n", false},
+		{"hash only", hash + "\n", false},
+		{"empty", "", false},
+	} {
+		t.Run(tc.name, func(t *testing.T) {
+			got, ok := lookupChecksum([]byte(tc.file), "dea_1.2.0_linux_amd64.tar.gz")
+			if ok != tc.want {
+				t.Fatalf("found = %v, want %v", ok, tc.want)
+			}
+			if ok && got != hash {
+				t.Errorf("hash = %q, want %q", got, hash)
+			}
+		})
+	}
+}
+
+func TestVerifyChecksumLayouts(t *testing.T) {
+	asset := []byte("archive bytes")
+	hash := sha256Hex(asset)
+
+	for _, line := range []string{
+		hash + "  dea.tar.gz\n",
+		hash + " *dea.tar.gz\n",
+		strings.ToUpper(hash) + " *dea.tar.gz\n",
+	} {
+		if err := verifyChecksum(asset, []byte(line), "dea.tar.gz"); err != nil {
+			t.Errorf("%q: %v", line, err)
+		}
+	}
+
+	if err := verifyChecksum([]byte("tampered"), []byte(hash+" *dea.tar.gz\n"), "dea.tar.gz"); err == nil {
+		t.Error("expected a mismatch for tampered data")
+	}
+	if err := verifyChecksum(asset, []byte(hash+"  other.tar.gz\n"), "dea.tar.gz"); err == nil ||
+		!strings.Contains(err.Error(), "no checksum entry") {
+		t.Errorf("err = %v, want a missing entry", err)
+	}
+}
+
+func TestFetchChecksumsCaches(t *testing.T) {
+	t.Setenv("DEA_HOME", t.TempDir())
+
+	var requests int
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests++
+		w.Write([]byte("abc  dea.tar.gz\n"))
+	}))
+	defer srv.Close()
+
+	for i := 0; i < 2; i++ {
+		data, err := fetchChecksums("v1.2.0", srv.URL+"/checksums.txt")
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(data) != "abc  dea.tar.gz\n" {
+			t.Errorf("checksums = %q", data)
+		}
+	}
+	if requests != 1 {
+		t.Errorf("downloaded %d times, want once", requests)
+	}
+
+	// Another release has its own checksums.
+	if _, err := fetchChecksums("v1.3.0", srv.URL+"/checksums.txt"); err != nil {
+		t.Fatal(err)
+	}
+	if requests != 2 {
+		t.Errorf("downloaded %d times, want a fresh download for a new tag", requests)
+	}
+}
+
+// archiveEntry is a file to put in a test archive.
+type archiveEntry struct {
+	name string
+	body string
+}
+
+func makeTarGz(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	gw := gzip.NewWriter(&buf)
+	tw := tar.NewWriter(gw)
+	for _, e := range entries {
+		hdr := &tar.Header{Name: e.name, Mode: 0755, Size: int64(len(e.body)), Typeflag: tar.TypeReg}
+		if err := tw.WriteHeader(hdr); err != nil {
+			t.Fatal(err)
+		}
+		if _, err := tw.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := tw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	if err := gw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func makeZip(t *testing.T, entries []archiveEntry) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, e := range entries {
+		w, err := zw.Create(e.name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(e.body)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestExtractBinary(t *testing.T) {
+	bin := "dea"
+	if runtime.GOOS == "windows" {
+		bin = "dea.exe"
+	}
+
+	for _, tc := range []struct {
+		name    string
+		entries []archiveEntry
+		want    string
+		wantErr string
+	}{
+		{"top level", []archiveEntry{{"README.md", "docs"}, {bin, "top"}}, "top", ""},
+		{"in a directory", []archiveEntry{{"dea_1.2.0/" + bin, "nested"}}, "nested", ""},
+		{"prefers top level", []archiveEntry{{"extras/plugins/" + bin, "deep"}, {bin, "top"}, {"tools/" + bin, "shallow"}}, "top", ""},
+		{"prefers shallowest", []archiveEntry{{"a/b/" + bin, "deep"}, {"a/" + bin, "shallow"}}, "shallow", ""},
+		{"missing", []archiveEntry{{"README.md", "docs"}}, "", "not found in archive"},
+		{"parent traversal", []archiveEntry{{bin, "top"}, {"../../" + bin, "evil"}}, "", "escapes the archive root"},
+		{"inner traversal", []archiveEntry{{"dir/../../" + bin, "evil"}}, "", "escapes the archive root"},
+		{"absolute", []archiveEntry{{"/usr/local/bin/" + bin, "evil"}}, "", "escapes the archive root"},
+		{"backslash traversal", []archiveEntry{{"..\\..\\" + bin, "evil"}}, "", "escapes the archive root"},
+		{"drive letter", []archiveEntry{{"C:\\Windows\\" + bin, "evil"}}, "", "escapes the archive root"},
+		{"traversal of another file", []archiveEntry{{bin, "top"}, {"../.bashrc", "evil"}}, "", "escapes the archive root"},
+	} {
+		for _, format := range []struct {
+			asset string
+			make  func(*testing.T, []archiveEntry) []byte
+		}{
+			{"dea.tar.gz", makeTarGz},
+			{"dea.zip", makeZip},
+		} {
+			t.Run(tc.name+"/"+format.asset, func(t *testing.T) {
+				got, err := extractBinary(format.make(t, tc.entries), format.asset)
+				if tc.wantErr != "" {
+					if err == nil || !strings.Contains(err.Error(), tc.wantErr) {
+						t.Fatalf("err = %v, want %q", err, tc.wantErr)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				if string(got) != tc.want {
+					t.Errorf("extracted %q, want %q", got, tc.want)
+				}
+			})
+		}
+	}
+}
+
+func TestExtractBinaryBareAsset(t *testing.T) {
+	data := []byte("\x7fELF...")
+	got, err := extractBinary(data, "dea_1.2.0_linux_amd64")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(got, data) {
+		t.Error("bare asset not returned as-is")
+	}
+}
@@ -0,0 +1,26 @@
+//go:build !windows
+
+package commands
+
+import (
+	"os"
+	"syscall"
+)
+
+// replaceBinary moves the new binary over the current one. rename(2) is
+// atomic on the same filesystem and safe while the old binary is running.
+func replaceBinary(newPath, execPath string) error {
+	return os.Rename(newPath, execPath)
+}
+
+// matchOwner gives path the same uid/gid as info, when they differ.
+func matchOwner(path string, info os.FileInfo) error {
+	st, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return nil
+	}
+	if int(st.Uid) == os.Getuid() && int(st.Gid) == os.Getgid() {
+		return nil
+	}
+	return os.Chown(path, int(st.Uid), int(st.Gid))
+}
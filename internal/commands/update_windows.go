@@ -0,0 +1,31 @@
+//go:build windows
+
+package commands
+
+import (
+	"os"
+)
+
+// replaceBinary swaps in the new binary. Windows refuses to overwrite a
+// running executable but does allow renaming it, so the current binary is
+// moved aside to <exe>.old (removed on the next run by cleanupOldBinary).
+func replaceBinary(newPath, execPath string) error {
+	oldPath := execPath + ".old"
+	_ = os.Remove(oldPath)
+
+	if err := os.Rename(execPath, oldPath); err != nil {
+		return err
+	}
+	if err := os.Rename(newPath, execPath); err != nil {
+		// Put the original back so the install is never left empty.
+		_ = os.Rename(oldPath, execPath)
+		return err
+	}
+	return nil
+}
+
+// matchOwner is a no-op on Windows, where files inherit ACLs from the
+// containing directory.
+func matchOwner(path string, info os.FileInfo) error {
+	return nil
+}
@@ -0,0 +1,182 @@
+package commands
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+	"github.com/spf13/cobra"
+)
+
+func newVaultCommand() *cobra.Command {
+	cmd := &cobra.Command{
+		Use:   "vault",
+		Short: "Store and retrieve workspace vault entries",
+	}
+
+	cmd.AddCommand(newVaultSetCommand())
+	cmd.AddCommand(newVaultGetCommand())
+	cmd.AddCommand(newVaultListCommand())
+
+	return cmd
+}
+
+func newVaultSetCommand() *cobra.Command {
+	var (
+		fromFile string
+		fromEnv  string
+	)
+
+	cmd := &cobra.Command{
+		Use:   "set <key> [value]",
+		Short: "Store a value in the workspace vault",
+		Long: `Store a value in the workspace vault. The value comes from exactly one of:
+the positional argument, --from-file, or --from-env. Prefer the flags for
+secrets so they never appear in shell history.`,
+		Args: cobra.RangeArgs(1, 2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+
+			sources := 0
+			for _, set := range []bool{len(args) == 2, fromFile != "", fromEnv != ""} {
+				if set {
+					sources++
+				}
+			}
+			if sources != 1 {
+				return fmt.Errorf("provide exactly one value source: <value>, --from-file, or --from-env")
+			}
+
+			var value string
+			switch {
+			case len(args) == 2:
+				value = args[1]
+			case fromFile != "":
+				data, err := os.ReadFile(fromFile)
+				if err != nil {
+					return fmt.Errorf("failed to read %s: %w", fromFile, err)
+				}
+				value = string(data)
+			case fromEnv != "":
+				v, ok := os.LookupEnv(fromEnv)
+				if !ok {
+					return fmt.Errorf("environment variable %s is not set", fromEnv)
+				}
+				value = v
+			}
+
+			mustLoadToken()
+
+			body := map[string]string{
+				"key":   key,
+				"value": value,
+			}
+
+			_, queued, err := apiPost(api.PathVault, body)
+			if err != nil {
+				return fmt.Errorf("failed to set vault key %s: %w", key, err)
+			}
+			if queued {
+				fmt.Println("Queued offline. Will flush on next connection.")
+				return nil
+			}
+
+			fmt.Printf("Stored vault key %s.\n", key)
+			return nil
+		},
+	}
+
+	cmd.Flags().StringVar(&fromFile, "from-file", "", "Read the value from a file")
+	cmd.Flags().StringVar(&fromEnv, "from-env", "", "Read the value from an environment variable")
+	return cmd
+}
+
+func newVaultGetCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "get <key>",
+		Short: "Print a vault value (raw, suitable for piping)",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			key := args[0]
+			mustLoadToken()
+
+			data, err := apiClient.Get(api.VaultPath(key))
+			if err != nil {
+				return handleAPIError(err, "vault key", key, "get")
+			}
+
+			entry, err := unwrapObject(data)
+			if err != nil {
+				return fmt.Errorf("unexpected vault response: %w", err)
+			}
+
+			value, ok := entry["value"]
+			if !ok {
+				return fmt.Errorf("vault key %s has no value", key)
+			}
+			if s, ok := value.(string); ok {
+				fmt.Print(s)
+				return nil
+			}
+			raw, err := json.Marshal(value)
+			if err != nil {
+				return err
+			}
+			fmt.Print(string(raw))
+			return nil
+		},
+	}
+}
+
+func newVaultListCommand() *cobra.Command {
+	return &cobra.Command{
+		Use:   "list",
+		Short: "List vault keys (values are never shown)",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			mustLoadToken()
+
+			data, err := apiClient.Get(api.PathVault)
+			if err != nil {
+				return handleAPIError(err, "vault", "entries", "list")
+			}
+
+			keys := vaultKeys(data)
+			if len(keys) == 0 {
+				fmt.Println("No vault entries.")
+				return nil
+			}
+
+			sort.Strings(keys)
+			for _, k := range keys {
+				fmt.Println(k)
+			}
+			return nil
+		},
+	}
+}
+
+// vaultKeys extracts entry keys from a vault list response, accepting a bare
+// array, { data: [...] } or { data: { entries: [...] } }. Entries may be
+// objects with a "key" field or plain strings.
+func vaultKeys(data []byte) []string {
+	v, err := unwrapData(data, "entries")
+	if err != nil {
+		return nil
+	}
+	entries, _ := v.([]interface{})
+
+	keys := make([]string, 0, len(entries))
+	for _, e := range entries {
+		switch v := e.(type) {
+		case string:
+			keys = append(keys, v)
+		case map[string]interface{}:
+			if k := strField(v, "key", ""); k != "" {
+				keys = append(keys, k)
+			}
+		}
+	}
+	return keys
+}
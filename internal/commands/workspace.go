@@ -2,6 +2,7 @@ package commands
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/spf13/cobra"
 )
@@ -19,8 +20,19 @@ func newWorkspaceCommand() *cobra.Command {
 	return cmd
 }
 
+// workspaceStatus is the structured form of `dea workspace status`.
+type workspaceStatus struct {
+	WorkspaceID string    `json:"workspace_id"`
+	AgentID     string    `json:"agent_id"`
+	Tier        string    `json:"tier"`
+	Endpoint    string    `json:"endpoint"`
+	TokenExpiry time.Time `json:"token_expiry"`
+}
+
 func newWorkspaceStatusCommand() *cobra.Command {
-	return &cobra.Command{
+	var out outputOptions
+
+	cmd := &cobra.Command{
 		Use:   "status",
 		Short: "Show workspace status",
 		RunE: func(cmd *cobra.Command, args []string) error {
@@ -52,6 +64,17 @@ func newWorkspaceStatusCommand() *cobra.Command {
 				endpoint = cfg.Endpoint
 			}
 
+			status := workspaceStatus{
+				WorkspaceID: workspaceID,
+				AgentID:     agentID,
+				Tier:        tier,
+				Endpoint:    endpoint,
+				TokenExpiry: token.ExpiresAt,
+			}
+			if ok, err := out.render(status); ok || err != nil {
+				return err
+			}
+
 			fmt.Printf("Workspace Status\n")
 			fmt.Printf("  Workspace ID: %s\n", workspaceID)
 			fmt.Printf("  Agent ID:     %s\n", agentID)
@@ -63,6 +86,9 @@ func newWorkspaceStatusCommand() *cobra.Command {
 			return nil
 		},
 	}
+
+	addOutputFlags(cmd, &out)
+	return cmd
 }
 
 func newWorkspaceSyncCommand() *cobra.Command {
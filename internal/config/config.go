@@ -1,39 +1,101 @@
 package config
 
 import (
+	"fmt"
 	"os"
 
 	"github.com/BurntSushi/toml"
 )
 
-// Config holds the dea CLI configuration loaded from ~/.dea/config.toml.
+// Config holds the dea CLI configuration loaded from config.toml in DeaDir.
 type Config struct {
 	Endpoint       string `toml:"endpoint"`
 	DefaultProject string `toml:"default_project"`
 	TimeoutSeconds int    `toml:"timeout_seconds"`
+
+	// DefaultSignalType is used by `dea signal` when --type is omitted.
+	DefaultSignalType string `toml:"default_signal_type"`
+
+	// ArtifactMaxSize rejects larger artifacts before upload, e.g. "100MB".
+	// Empty means no limit.
+	ArtifactMaxSize string `toml:"artifact_max_size"`
+
+	// LaneAliases maps lane names typed on the command line to database
+	// lane names, e.g. wip = "in_progress".
+	LaneAliases map[string]string `toml:"lane_aliases"`
+
+	// SignalOnTransition makes `dea transition` record each move as a
+	// signal of type SignalOnTransitionType, for an audit trail. Off by
+	// default.
+	SignalOnTransition     bool   `toml:"signal_on_transition"`
+	SignalOnTransitionType string `toml:"signal_on_transition_type"`
+
+	// RefreshOnUnauthorized refreshes the token and retries once when a
+	// request is rejected with 401. On by default.
+	RefreshOnUnauthorized bool `toml:"refresh_on_unauthorized"`
+
+	// MaxBodySize caps API response bodies read into memory, e.g. "64MB".
+	// Empty means the client default.
+	MaxBodySize string `toml:"max_body_size"`
+
+	// RequestsPerSecond paces API calls on the client side so batch
+	// operations don't trip server rate limits. Zero (the default) means
+	// unpaced.
+	RequestsPerSecond float64 `toml:"requests_per_second"`
+
+	// Headers are added to every API request, e.g. a key required by a
+	// gateway in front of a self-hosted deployment. Values may reference
+	// environment variables as ${NAME}; they are expanded when the client
+	// is built, so the secret itself need not be stored here.
+	Headers map[string]string `toml:"headers"`
+
+	// MaxIdleConns and IdleConnTimeoutSeconds tune how many connections to
+	// the endpoint are kept open between requests, and for how long. Zero
+	// means the client defaults.
+	MaxIdleConns           int `toml:"max_idle_conns"`
+	IdleConnTimeoutSeconds int `toml:"idle_conn_timeout_seconds"`
+
+	// ContextDir, when set, is an absolute directory (~ allowed) that holds
+	// pulled card context, the current card and staged artifacts in a
+	// subdirectory per workspace, instead of .dea-context in the working
+	// directory.
+	ContextDir string `toml:"context_dir"`
+
+	// DashboardURL is the web UI's card page base; `dea pull card --open`
+	// appends the card ID to it.
+	DashboardURL string `toml:"dashboard_url"`
 }
 
-// Load reads the config from ~/.dea/config.toml. Returns defaults if the file
-// does not exist.
+// Load reads the global config from ConfigPath(), then the repo-local
+// .dea/config.toml from LocalConfigPath() over it, so settings in the repo
+// win. Missing files are skipped; with neither, defaults are returned.
 func Load() (*Config, error) {
 	cfg := &Config{
 		Endpoint:       DefaultEndpoint,
 		DefaultProject: DefaultProject,
 		TimeoutSeconds: DefaultTimeoutSeconds,
-	}
 
-	path := ConfigPath()
-	if _, err := os.Stat(path); os.IsNotExist(err) {
-		return cfg, nil
+		SignalOnTransitionType: DefaultTransitionSignalType,
+		RefreshOnUnauthorized:  true,
 	}
 
-	if _, err := toml.DecodeFile(path, cfg); err != nil {
-		return nil, err
+	for _, path := range []string{ConfigPath(), LocalConfigPath()} {
+		if path == "" {
+			continue
+		}
+		if _, err := os.Stat(path); os.IsNotExist(err) {
+			continue
+		}
+		// Decoding into the same struct only overwrites keys present in
+		// the file, which merges it over what was loaded before.
+		if _, err := toml.DecodeFile(path, cfg); err != nil {
+			return nil, fmt.Errorf("%s: %w", path, err)
+		}
 	}
 	return cfg, nil
 }
 
-// Save writes the config to ~/.dea/config.toml.
+// Save writes the config to ConfigPath().
 func Save(cfg *Config) error {
 	if err := os.MkdirAll(DeaDir(), 0700); err != nil {
 		return err
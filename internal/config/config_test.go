@@ -0,0 +1,90 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// chdir changes into dir for the rest of the test.
+func chdir(t *testing.T, dir string) {
+	t.Helper()
+	wd, err := os.Getwd()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Chdir(dir); err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.Chdir(wd) })
+}
+
+func TestLoadPrecedence(t *testing.T) {
+	_, dea := setDirs(t)
+	repo := t.TempDir()
+	sub := filepath.Join(repo, "pkg", "inner")
+	if err := os.MkdirAll(sub, 0700); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, sub)
+
+	// Defaults only.
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != DefaultEndpoint || cfg.TimeoutSeconds != DefaultTimeoutSeconds {
+		t.Errorf("defaults: got endpoint %q timeout %d", cfg.Endpoint, cfg.TimeoutSeconds)
+	}
+
+	// Global over defaults.
+	writeFile(t, filepath.Join(dea, "config.toml"),
+		"endpoint = \"https://global\"\ntimeout_seconds = 10\ndefault_signal_type = \"friction\"\n")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "https://global" || cfg.TimeoutSeconds != 10 {
+		t.Errorf("global: got endpoint %q timeout %d", cfg.Endpoint, cfg.TimeoutSeconds)
+	}
+	if cfg.DefaultProject != DefaultProject {
+		t.Errorf("global: unset default_project = %q, want default", cfg.DefaultProject)
+	}
+
+	// Repo-local over global, found from a subdirectory; unset keys fall
+	// through to the global file.
+	writeFile(t, filepath.Join(repo, ".dea", "config.toml"), "endpoint = \"https://repo\"\n")
+	cfg, err = Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "https://repo" {
+		t.Errorf("local: endpoint = %q, want https://repo", cfg.Endpoint)
+	}
+	if cfg.TimeoutSeconds != 10 || cfg.DefaultSignalType != "friction" {
+		t.Errorf("local: global keys lost: timeout %d, signal type %q", cfg.TimeoutSeconds, cfg.DefaultSignalType)
+	}
+}
+
+func TestLoadIgnoresLegacyConfigUnderHome(t *testing.T) {
+	legacy, dea := setDirs(t)
+	writeFile(t, filepath.Join(legacy, "config.toml"), "endpoint = \"https://stale\"\n")
+	writeFile(t, filepath.Join(dea, "config.toml"), "endpoint = \"https://global\"\n")
+
+	work := filepath.Join(filepath.Dir(legacy), "src", "project")
+	if err := os.MkdirAll(work, 0700); err != nil {
+		t.Fatal(err)
+	}
+	chdir(t, work)
+
+	if path := LocalConfigPath(); path != "" {
+		t.Errorf("LocalConfigPath() = %q, want none", path)
+	}
+	cfg, err := Load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if cfg.Endpoint != "https://global" {
+		t.Errorf("endpoint = %q, want https://global", cfg.Endpoint)
+	}
+}
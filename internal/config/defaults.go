@@ -9,10 +9,29 @@ const (
 	DefaultEndpoint       = "https://hehldpjqlxhshdqqadng.supabase.co/functions/v1"
 	DefaultTimeoutSeconds = 30
 	DefaultProject        = "workspace-runtime"
+
+	// DefaultTransitionSignalType is the signal type recorded for each
+	// transition when signal_on_transition is enabled.
+	DefaultTransitionSignalType = "pattern"
 )
 
-// DeaDir returns the ~/.dea directory path.
+// DeaDir returns the dea state directory. Resolution order:
+//
+//  1. $DEA_HOME, if set (explicit override)
+//  2. $XDG_CONFIG_HOME/dea, if XDG_CONFIG_HOME is set
+//  3. ~/.dea (legacy location, kept for backward compatibility)
 func DeaDir() string {
+	if dir := os.Getenv("DEA_HOME"); dir != "" {
+		return dir
+	}
+	if xdg := os.Getenv("XDG_CONFIG_HOME"); xdg != "" {
+		return filepath.Join(xdg, "dea")
+	}
+	return LegacyDeaDir()
+}
+
+// LegacyDeaDir returns the pre-XDG ~/.dea directory path.
+func LegacyDeaDir() string {
 	home, err := os.UserHomeDir()
 	if err != nil {
 		return ".dea"
@@ -20,17 +39,50 @@ func DeaDir() string {
 	return filepath.Join(home, ".dea")
 }
 
-// ConfigPath returns the path to ~/.dea/config.toml.
+// ConfigPath returns the path to config.toml inside DeaDir.
 func ConfigPath() string {
 	return filepath.Join(DeaDir(), "config.toml")
 }
 
-// TokensPath returns the path to ~/.dea/tokens.json.
+// LocalConfigPath returns the nearest .dea/config.toml found by walking up
+// from the working directory, or "" if there is none. The walk stops below
+// $HOME, and neither the global config nor the legacy ~/.dea/config.toml is
+// ever returned, so a stale legacy file isn't mistaken for a repo's.
+func LocalConfigPath() string {
+	dir, err := os.Getwd()
+	if err != nil {
+		return ""
+	}
+	home, _ := os.UserHomeDir()
+	if home != "" {
+		home = filepath.Clean(home)
+	}
+	global := filepath.Clean(ConfigPath())
+	legacy := filepath.Join(LegacyDeaDir(), "config.toml")
+	for {
+		if dir == home {
+			return ""
+		}
+		path := filepath.Join(dir, ".dea", "config.toml")
+		if path != global && path != legacy {
+			if info, err := os.Stat(path); err == nil && !info.IsDir() {
+				return path
+			}
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return ""
+		}
+		dir = parent
+	}
+}
+
+// TokensPath returns the path to tokens.json inside DeaDir.
 func TokensPath() string {
 	return filepath.Join(DeaDir(), "tokens.json")
 }
 
-// QueuePath returns the path to ~/.dea/queue.json.
+// QueuePath returns the path to queue.json inside DeaDir.
 func QueuePath() string {
 	return filepath.Join(DeaDir(), "queue.json")
 }
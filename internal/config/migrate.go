@@ -0,0 +1,89 @@
+package config
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// legacyFiles are the state files that lived directly in ~/.dea before
+// DeaDir honored DEA_HOME and XDG_CONFIG_HOME.
+var legacyFiles = []string{"config.toml", "tokens.json", "queue.json"}
+
+// migratedMarker is created in the legacy directory once it has been
+// migrated. Later runs skip migration, so state removed from DeaDir since,
+// e.g. by `dea auth revoke`, isn't copied back from ~/.dea.
+const migratedMarker = ".migrated"
+
+// MigrateLegacy copies state files from the legacy ~/.dea directory into
+// DeaDir when the two differ, then marks the legacy directory migrated so
+// it happens only once. Files that already exist at the destination are
+// never overwritten. Returns a human-readable line for each file that was
+// migrated.
+func MigrateLegacy() ([]string, error) {
+	src := LegacyDeaDir()
+	dst := DeaDir()
+	if filepath.Clean(src) == filepath.Clean(dst) {
+		return nil, nil
+	}
+	if info, err := os.Stat(src); err != nil || !info.IsDir() {
+		return nil, nil
+	}
+	marker := filepath.Join(src, migratedMarker)
+	if _, err := os.Stat(marker); err == nil {
+		return nil, nil
+	}
+
+	var migrated []string
+	for _, name := range legacyFiles {
+		from := filepath.Join(src, name)
+		to := filepath.Join(dst, name)
+
+		if _, err := os.Stat(from); err != nil {
+			continue
+		}
+		if _, err := os.Stat(to); err == nil {
+			continue
+		}
+
+		if err := os.MkdirAll(dst, 0700); err != nil {
+			return migrated, err
+		}
+		if err := copyFile(from, to); err != nil {
+			return migrated, fmt.Errorf("failed to migrate %s: %w", from, err)
+		}
+		migrated = append(migrated, fmt.Sprintf("%s -> %s", from, to))
+	}
+
+	note := fmt.Sprintf("Migrated to %s. dea no longer reads state from this directory.\n", dst)
+	if err := os.WriteFile(marker, []byte(note), 0600); err != nil {
+		return migrated, fmt.Errorf("failed to mark %s migrated: %w", src, err)
+	}
+	return migrated, nil
+}
+
+// copyFile copies src to dst, preserving the source file's permission bits.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		_ = os.Remove(dst)
+		return err
+	}
+	return out.Close()
+}
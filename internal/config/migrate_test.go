@@ -0,0 +1,94 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// setDirs points LegacyDeaDir at home/.dea and DeaDir at a separate
+// directory, returning both.
+func setDirs(t *testing.T) (legacy, dea string) {
+	t.Helper()
+	home := t.TempDir()
+	t.Setenv("HOME", home)
+	t.Setenv("XDG_CONFIG_HOME", "")
+	dea = filepath.Join(t.TempDir(), "dea")
+	t.Setenv("DEA_HOME", dea)
+	legacy = filepath.Join(home, ".dea")
+	if err := os.MkdirAll(legacy, 0700); err != nil {
+		t.Fatal(err)
+	}
+	return legacy, dea
+}
+
+func writeFile(t *testing.T, path, content string) {
+	t.Helper()
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestMigrateLegacyRunsOnce(t *testing.T) {
+	legacy, dea := setDirs(t)
+	writeFile(t, filepath.Join(legacy, "tokens.json"), `{"workspace_token":"old"}`)
+
+	migrated, err := MigrateLegacy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 1 {
+		t.Fatalf("migrated = %v, want tokens.json only", migrated)
+	}
+	if _, err := os.Stat(filepath.Join(dea, "tokens.json")); err != nil {
+		t.Fatalf("tokens.json not copied: %v", err)
+	}
+
+	// Revoking removes the token; the next run must not bring it back.
+	if err := os.Remove(filepath.Join(dea, "tokens.json")); err != nil {
+		t.Fatal(err)
+	}
+	migrated, err = MigrateLegacy()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(migrated) != 0 {
+		t.Errorf("second run migrated %v, want nothing", migrated)
+	}
+	if _, err := os.Stat(filepath.Join(dea, "tokens.json")); !os.IsNotExist(err) {
+		t.Errorf("tokens.json came back after removal (stat err %v)", err)
+	}
+}
+
+func TestMigrateLegacyKeepsExisting(t *testing.T) {
+	legacy, dea := setDirs(t)
+	writeFile(t, filepath.Join(legacy, "config.toml"), `endpoint = "https://old"`)
+	writeFile(t, filepath.Join(dea, "config.toml"), `endpoint = "https://new"`)
+
+	if _, err := MigrateLegacy(); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(filepath.Join(dea, "config.toml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != `endpoint = "https://new"` {
+		t.Errorf("config.toml overwritten: %q", data)
+	}
+}
+
+func TestMigrateLegacyWithoutLegacyDir(t *testing.T) {
+	legacy, _ := setDirs(t)
+	if err := os.RemoveAll(legacy); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := MigrateLegacy(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := os.Stat(legacy); !os.IsNotExist(err) {
+		t.Errorf("legacy dir created: %v", err)
+	}
+}
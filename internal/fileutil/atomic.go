@@ -0,0 +1,53 @@
+package fileutil
+
+import (
+	"bytes"
+	"io"
+	"os"
+	"path/filepath"
+)
+
+// rename is os.Rename, replaceable so tests can interrupt a write just
+// before the file is replaced.
+var rename = os.Rename
+
+// WriteFileAtomic writes data to a temp file in path's directory and renames
+// it over path. Rename is atomic on the same filesystem, so readers see either
+// the old contents or the new, never a truncated file.
+func WriteFileAtomic(path string, data []byte, perm os.FileMode) error {
+	return WriteReaderAtomic(path, bytes.NewReader(data), perm)
+}
+
+// WriteReaderAtomic is WriteFileAtomic for content streamed from r, so large
+// files need not be held in memory.
+func WriteReaderAtomic(path string, r io.Reader, perm os.FileMode) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), "."+filepath.Base(path)+"-*.tmp")
+	if err != nil {
+		return err
+	}
+	tmpPath := tmp.Name()
+
+	if _, err := io.Copy(tmp, r); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := os.Chmod(tmpPath, perm); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	if err := rename(tmpPath, path); err != nil {
+		os.Remove(tmpPath)
+		return err
+	}
+	return nil
+}
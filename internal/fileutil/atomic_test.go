@@ -0,0 +1,125 @@
+package fileutil
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+const oldContent = `{"workspace_token":"old"}`
+
+// existingFile returns the path of a file holding oldContent in a temp dir.
+func existingFile(t *testing.T) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	if err := os.WriteFile(path, []byte(oldContent), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func assertOldContent(t *testing.T, path string) {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != oldContent {
+		t.Errorf("%s = %q, want the old contents", path, data)
+	}
+}
+
+func tempFiles(t *testing.T, dir string) []string {
+	t.Helper()
+	matches, err := filepath.Glob(filepath.Join(dir, ".*.tmp"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return matches
+}
+
+func TestWriteFileAtomicReplaces(t *testing.T) {
+	path := existingFile(t)
+	if err := WriteFileAtomic(path, []byte("new"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if string(data) != "new" {
+		t.Errorf("contents = %q, want new", data)
+	}
+	if left := tempFiles(t, filepath.Dir(path)); len(left) != 0 {
+		t.Errorf("temp files left: %v", left)
+	}
+}
+
+// failingReader returns some data and then an error, like a write cut off
+// part way.
+type failingReader struct{ sent bool }
+
+func (r *failingReader) Read(p []byte) (int, error) {
+	if r.sent {
+		return 0, errors.New("interrupted")
+	}
+	r.sent = true
+	return copy(p, `{"workspace_tok`), nil
+}
+
+func TestWriteReaderAtomicInterruptedWrite(t *testing.T) {
+	path := existingFile(t)
+	if err := WriteReaderAtomic(path, &failingReader{}, 0600); err == nil {
+		t.Fatal("expected an error")
+	}
+	assertOldContent(t, path)
+	if left := tempFiles(t, filepath.Dir(path)); len(left) != 0 {
+		t.Errorf("temp files left: %v", left)
+	}
+}
+
+func TestWriteFileAtomicInterruptedBeforeRename(t *testing.T) {
+	path := existingFile(t)
+
+	// The new contents are fully written to the temp file, then the process
+	// "dies" before the rename.
+	var written string
+	rename = func(from, to string) error {
+		data, err := os.ReadFile(from)
+		if err != nil {
+			t.Fatal(err)
+		}
+		written = string(data)
+		return errors.New("killed")
+	}
+	defer func() { rename = os.Rename }()
+
+	if err := WriteFileAtomic(path, []byte(`{"workspace_token":"new"}`), 0600); err == nil {
+		t.Fatal("expected an error")
+	}
+	if !strings.Contains(written, "new") {
+		t.Fatalf("temp file held %q before the rename, want the new contents", written)
+	}
+	assertOldContent(t, path)
+	if left := tempFiles(t, filepath.Dir(path)); len(left) != 0 {
+		t.Errorf("temp files left: %v", left)
+	}
+}
+
+func TestWriteFileAtomicCrashBeforeRename(t *testing.T) {
+	path := existingFile(t)
+
+	// A crash leaves the temp file behind; the real file must still read
+	// as before.
+	rename = func(from, to string) error { panic("crash") }
+	defer func() { rename = os.Rename }()
+
+	func() {
+		defer func() { recover() }()
+		WriteReaderAtomic(path, strings.NewReader(`{"workspace_token":"new"}`), 0600)
+	}()
+
+	assertOldContent(t, path)
+}
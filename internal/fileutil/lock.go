@@ -0,0 +1,43 @@
+package fileutil
+
+import (
+	"fmt"
+	"os"
+	"time"
+)
+
+const (
+	lockPollInterval = 50 * time.Millisecond
+
+	// lockStaleAfter is how old a lock file must be before it is assumed to
+	// belong to a process that died without releasing it.
+	lockStaleAfter = 30 * time.Second
+)
+
+// Lock takes an exclusive lock by creating path with O_EXCL, polling until
+// timeout if another process holds it. The returned func releases the lock.
+// A plain lock file is used rather than flock so behaviour is the same on
+// every platform.
+func Lock(path string, timeout time.Duration) (func(), error) {
+	deadline := time.Now().Add(timeout)
+	for {
+		f, err := os.OpenFile(path, os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0600)
+		if err == nil {
+			fmt.Fprintf(f, "%d\n", os.Getpid())
+			f.Close()
+			return func() { os.Remove(path) }, nil
+		}
+		if !os.IsExist(err) {
+			return nil, err
+		}
+
+		if info, statErr := os.Stat(path); statErr == nil && time.Since(info.ModTime()) > lockStaleAfter {
+			os.Remove(path)
+			continue
+		}
+		if time.Now().After(deadline) {
+			return nil, fmt.Errorf("timed out waiting for lock %s", path)
+		}
+		time.Sleep(lockPollInterval)
+	}
+}
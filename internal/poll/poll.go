@@ -0,0 +1,106 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"math/rand"
+	"time"
+)
+
+// ErrMaxAttempts is returned by Until when fn was called Options.MaxAttempts
+// times without reporting done.
+var ErrMaxAttempts = errors.New("gave up after maximum attempts")
+
+// Options controls how often Until calls its function.
+type Options struct {
+	// Interval is the wait before the second call. Required.
+	Interval time.Duration
+
+	// Multiplier grows the wait after each call. Values <= 1 keep the wait
+	// constant.
+	Multiplier float64
+
+	// MaxInterval caps the wait when Multiplier grows it. Zero means no cap.
+	MaxInterval time.Duration
+
+	// Jitter randomizes each wait by up to this fraction in either
+	// direction, e.g. 0.1 for ±10%, so many clients don't poll in lockstep.
+	Jitter float64
+
+	// MaxAttempts stops polling after this many calls. Zero means no limit.
+	MaxAttempts int
+
+	// Immediate makes the first call before any wait. Otherwise Until waits
+	// one Interval first.
+	Immediate bool
+}
+
+// Until calls fn until it reports done, returns an error, MaxAttempts is
+// reached, or ctx is cancelled. It returns fn's error, ErrMaxAttempts, or
+// ctx.Err() respectively, and nil once fn is done.
+func Until(ctx context.Context, opts Options, fn func(ctx context.Context) (done bool, err error)) error {
+	wait := opts.Interval
+	if !opts.Immediate {
+		if err := sleep(ctx, jitter(wait, opts.Jitter)); err != nil {
+			return err
+		}
+	}
+
+	for attempt := 1; ; attempt++ {
+		done, err := fn(ctx)
+		if err != nil {
+			return err
+		}
+		if done {
+			return nil
+		}
+		if opts.MaxAttempts > 0 && attempt >= opts.MaxAttempts {
+			return ErrMaxAttempts
+		}
+
+		if err := sleep(ctx, jitter(wait, opts.Jitter)); err != nil {
+			return err
+		}
+		wait = next(wait, opts)
+	}
+}
+
+// next returns the wait to use after d.
+func next(d time.Duration, opts Options) time.Duration {
+	if opts.Multiplier <= 1 {
+		return d
+	}
+	d = time.Duration(float64(d) * opts.Multiplier)
+	if opts.MaxInterval > 0 && d > opts.MaxInterval {
+		d = opts.MaxInterval
+	}
+	return d
+}
+
+// jitter spreads d uniformly over [d-d*frac, d+d*frac].
+func jitter(d time.Duration, frac float64) time.Duration {
+	if frac <= 0 || d <= 0 {
+		return d
+	}
+	if frac > 1 {
+		frac = 1
+	}
+	delta := float64(d) * frac
+	return time.Duration(float64(d) - delta + rand.Float64()*2*delta)
+}
+
+// sleep is sleepContext, replaceable so tests can record waits instead of
+// sitting through them.
+var sleep = sleepContext
+
+// sleepContext waits for d, returning ctx.Err() if ctx is cancelled first.
+func sleepContext(ctx context.Context, d time.Duration) error {
+	t := time.NewTimer(d)
+	defer t.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-t.C:
+		return nil
+	}
+}
@@ -0,0 +1,163 @@
+package poll
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// recordWaits replaces sleep for the test, returning the waits Until asked
+// for in place of sleeping.
+func recordWaits(t *testing.T) *[]time.Duration {
+	t.Helper()
+	var waits []time.Duration
+	sleep = func(ctx context.Context, d time.Duration) error {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		waits = append(waits, d)
+		return nil
+	}
+	t.Cleanup(func() { sleep = sleepContext })
+	return &waits
+}
+
+// doneAfter returns a function reporting done on its nth call.
+func doneAfter(n int, calls *int) func(context.Context) (bool, error) {
+	return func(context.Context) (bool, error) {
+		*calls++
+		return *calls >= n, nil
+	}
+}
+
+func TestUntilBacksOff(t *testing.T) {
+	waits := recordWaits(t)
+	var calls int
+
+	err := Until(context.Background(), Options{
+		Interval:    time.Second,
+		Multiplier:  2,
+		MaxInterval: 5 * time.Second,
+		Immediate:   true,
+	}, doneAfter(6, &calls))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if calls != 6 {
+		t.Errorf("called %d times, want 6", calls)
+	}
+	want := []time.Duration{time.Second, 2 * time.Second, 4 * time.Second, 5 * time.Second, 5 * time.Second}
+	if !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestUntilConstantInterval(t *testing.T) {
+	waits := recordWaits(t)
+	var calls int
+
+	// Without Immediate the first call waits too; Multiplier <= 1 keeps the
+	// wait fixed.
+	if err := Until(context.Background(), Options{Interval: time.Second, Multiplier: 1}, doneAfter(3, &calls)); err != nil {
+		t.Fatal(err)
+	}
+	want := []time.Duration{time.Second, time.Second, time.Second}
+	if !reflect.DeepEqual(*waits, want) {
+		t.Errorf("waits = %v, want %v", *waits, want)
+	}
+}
+
+func TestUntilMaxAttempts(t *testing.T) {
+	waits := recordWaits(t)
+	var calls int
+
+	err := Until(context.Background(), Options{Interval: time.Second, Multiplier: 2, MaxAttempts: 3, Immediate: true}, doneAfter(10, &calls))
+	if !errors.Is(err, ErrMaxAttempts) {
+		t.Fatalf("err = %v, want ErrMaxAttempts", err)
+	}
+	if calls != 3 {
+		t.Errorf("called %d times, want 3", calls)
+	}
+	if len(*waits) != 2 {
+		t.Errorf("waited %v, want no wait after the last attempt", *waits)
+	}
+}
+
+func TestUntilStopsOnError(t *testing.T) {
+	recordWaits(t)
+	boom := errors.New("boom")
+	var calls int
+
+	err := Until(context.Background(), Options{Interval: time.Second, Immediate: true}, func(context.Context) (bool, error) {
+		calls++
+		if calls == 2 {
+			return false, boom
+		}
+		return false, nil
+	})
+	if !errors.Is(err, boom) {
+		t.Errorf("err = %v, want boom", err)
+	}
+	if calls != 2 {
+		t.Errorf("called %d times, want 2", calls)
+	}
+}
+
+func TestUntilCancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	var calls int
+
+	// Real sleeps: cancelling must cut the wait short.
+	start := time.Now()
+	err := Until(ctx, Options{Interval: time.Hour, Immediate: true}, func(context.Context) (bool, error) {
+		calls++
+		cancel()
+		return false, nil
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("err = %v, want context.Canceled", err)
+	}
+	if calls != 1 {
+		t.Errorf("called %d times, want 1", calls)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("took %v to notice cancellation", elapsed)
+	}
+}
+
+func TestUntilJitter(t *testing.T) {
+	waits := recordWaits(t)
+	var calls int
+
+	if err := Until(context.Background(), Options{Interval: 10 * time.Second, Jitter: 0.1, Immediate: true}, doneAfter(50, &calls)); err != nil {
+		t.Fatal(err)
+	}
+	varied := false
+	for _, w := range *waits {
+		if w < 9*time.Second || w > 11*time.Second {
+			t.Errorf("wait %v outside 10s ±10%%", w)
+		}
+		if w != 10*time.Second {
+			varied = true
+		}
+	}
+	if !varied {
+		t.Error("jitter never changed the wait")
+	}
+}
+
+func TestJitterBounds(t *testing.T) {
+	if got := jitter(time.Second, 0); got != time.Second {
+		t.Errorf("jitter with 0 = %v, want unchanged", got)
+	}
+	if got := jitter(0, 0.5); got != 0 {
+		t.Errorf("jitter of 0 = %v, want 0", got)
+	}
+	for i := 0; i < 100; i++ {
+		if got := jitter(time.Second, 5); got < 0 || got > 2*time.Second {
+			t.Fatalf("jitter with fraction > 1 = %v, want within [0, 2s]", got)
+		}
+	}
+}
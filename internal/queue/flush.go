@@ -1,55 +1,193 @@
 package queue
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
+	"io"
+	"os"
 
 	"github.com/dea-exmachina/dea-cli/internal/api"
 )
 
-// Flush attempts to replay all queued requests against the API.
-// Successfully replayed requests are removed from the queue.
-// Returns the number of items flushed and any error encountered.
-func Flush(q *Queue, client *api.Client) (int, error) {
+// Warnf reports queued requests Flush skips, drops or fails to replay. It
+// writes to stderr by default; the CLI replaces it so these messages follow
+// --log-format and stay off stdout.
+var Warnf = func(format string, args ...interface{}) {
+	fmt.Fprintf(os.Stderr, "warning: "+format+"\n", args...)
+}
+
+// Flush attempts to replay all queued requests against the API, oldest
+// first. Successfully replayed requests are removed from the queue.
+// Flushing stops at the first network, rate limit or authentication
+// failure, keeping the rest for next time.
+//
+// With pruneAfter zero, a request the API rejects (4xx) is removed at once
+// and a server error (5xx) stops the flush. With pruneAfter above zero,
+// either failure records an attempt on the request, which is kept (a 5xx
+// still stops the flush) until it has failed more than pruneAfter times and
+// is removed, so a poison request isn't retried forever. Requests already
+// past that are removed without being sent.
+//
+// A request that depends on another (see QueuedRequest.DependsOn) waits
+// while that one stays queued, and is dropped when it is.
+//
+// Returns the number of items flushed and the number pruned.
+func Flush(q *Queue, client *api.Client, pruneAfter int) (flushed, pruned int, err error) {
 	items, err := q.List()
 	if err != nil {
-		return 0, fmt.Errorf("failed to load queue: %w", err)
+		return 0, 0, fmt.Errorf("failed to load queue: %w", err)
 	}
 
-	if len(items) == 0 {
-		return 0, nil
+	// drop removes a request that won't be replayed, along with the
+	// queued requests that depend on it.
+	gone := map[string]bool{}
+	drop := func(item QueuedRequest) {
+		gone[item.ID] = true
+		dependents, err := q.Drop(item.ID)
+		if err != nil {
+			Warnf("failed to remove %s: %v", item.ID, err)
+		}
+		for _, d := range dependents {
+			gone[d.ID] = true
+			Warnf("dropped queued request %s %s: it depends on removed request %s", d.Method, d.Path, item.ID)
+		}
+	}
+	// held marks requests left in the queue after being tried, so requests
+	// that depend on them wait too.
+	held := map[string]bool{}
+
+	// Drop requests already known to be dead before sending anything, so
+	// they go even if the flush stops early.
+	if pruneAfter > 0 {
+		live := items[:0]
+		for _, item := range items {
+			if item.Attempts <= pruneAfter {
+				live = append(live, item)
+				continue
+			}
+			Warnf("pruned queued request %s %s: failed %d time(s)", item.Method, item.Path, item.Attempts)
+			drop(item)
+			pruned++
+		}
+		items = live
 	}
 
-	flushed := 0
 	for _, item := range items {
+		if gone[item.ID] {
+			continue
+		}
+		if item.DependsOn != "" && held[item.DependsOn] {
+			// What it refers to is still queued; keep it for next time.
+			held[item.ID] = true
+			continue
+		}
+		if err := verifyQueuedArtifact(item); err != nil {
+			Warnf("skipping queued artifact %s: %v (removing; push it again)", item.ID, err)
+			drop(item)
+			continue
+		}
+
 		var respErr error
 		switch item.Method {
 		case "POST":
 			_, respErr = client.Post(item.Path, item.Body)
 		case "GET":
 			_, respErr = client.Get(item.Path)
+		case "DELETE":
+			_, respErr = client.Delete(item.Path)
 		default:
 			// Unknown method — skip and remove to avoid infinite retry.
-			fmt.Printf("Skipping unsupported queued method %s %s\n", item.Method, item.Path)
-			_ = q.Remove(item.ID)
+			Warnf("skipping unsupported queued method %s %s", item.Method, item.Path)
+			drop(item)
 			continue
 		}
 
 		if respErr != nil {
-			if api.IsNetworkError(respErr) {
-				// Still offline — stop flushing.
-				break
+			category := api.Classify(respErr)
+			switch category {
+			case api.CategoryNetwork, api.CategoryRateLimited, api.CategoryUnauthorized:
+				// Nothing wrong with the request itself — stop flushing
+				// and keep the rest for next time.
+				return flushed, pruned, nil
+			}
+
+			attempts := item.Attempts + 1
+			if pruneAfter > 0 {
+				if _, err := q.RecordFailure(item.ID); err != nil {
+					Warnf("failed to record attempt for %s: %v", item.ID, err)
+				}
+				if attempts > pruneAfter {
+					Warnf("pruned queued request %s %s: failed %d time(s), last: %v", item.Method, item.Path, attempts, respErr)
+					drop(item)
+					pruned++
+					continue
+				}
 			}
-			// Non-network error (e.g. 4xx) — remove from queue to avoid infinite retry.
-			fmt.Printf("Queued request %s failed with non-network error: %v (removing)\n", item.ID, respErr)
-			_ = q.Remove(item.ID)
+			if category == api.CategoryServer {
+				// The server is failing — stop flushing and keep the rest
+				// for next time.
+				Warnf("queued request %s failed with a server error: %v (stopping; will retry)", item.ID, respErr)
+				return flushed, pruned, nil
+			}
+			if pruneAfter > 0 {
+				Warnf("queued request %s failed (attempt %d): %v", item.ID, attempts, respErr)
+				held[item.ID] = true
+				continue
+			}
+			// Rejected by the API (e.g. 4xx) — remove from queue to avoid infinite retry.
+			Warnf("queued request %s failed with non-retryable error: %v (removing)", item.ID, respErr)
+			drop(item)
 			continue
 		}
 
 		if err := q.Remove(item.ID); err != nil {
-			fmt.Printf("Warning: failed to remove flushed item %s: %v\n", item.ID, err)
+			Warnf("failed to remove flushed item %s: %v", item.ID, err)
 		}
 		flushed++
 	}
 
-	return flushed, nil
+	return flushed, pruned, nil
+}
+
+// queuedArtifact is the part of a queued artifact registration that
+// describes the local file as it was when the push was queued.
+type queuedArtifact struct {
+	StoragePath string `json:"storage_path"`
+	FileHash    string `json:"file_hash"`
+	FileSize    *int64 `json:"file_size"`
+}
+
+// verifyQueuedArtifact checks that the file behind a queued artifact
+// registration still has the size and hash recorded at push time, so a file
+// edited while offline isn't registered under a hash it no longer has.
+// Other requests, and registrations without a recorded hash, pass.
+func verifyQueuedArtifact(item QueuedRequest) error {
+	if item.Method != "POST" || item.Path != api.PathArtifacts || len(item.Body) == 0 {
+		return nil
+	}
+	var a queuedArtifact
+	if err := json.Unmarshal(item.Body, &a); err != nil || a.StoragePath == "" || a.FileHash == "" {
+		return nil
+	}
+
+	f, err := os.Open(a.StoragePath)
+	if err != nil {
+		return fmt.Errorf("%s is no longer readable: %w", a.StoragePath, err)
+	}
+	defer f.Close()
+
+	h := sha256.New()
+	size, err := io.Copy(h, f)
+	if err != nil {
+		return fmt.Errorf("cannot read %s: %w", a.StoragePath, err)
+	}
+	if a.FileSize != nil && size != *a.FileSize {
+		return fmt.Errorf("%s changed since it was queued (size %d, was %d)", a.StoragePath, size, *a.FileSize)
+	}
+	if hex.EncodeToString(h.Sum(nil)) != a.FileHash {
+		return fmt.Errorf("%s changed since it was queued (hash mismatch)", a.StoragePath)
+	}
+	return nil
 }
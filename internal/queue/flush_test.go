@@ -0,0 +1,223 @@
+package queue
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/dea-exmachina/dea-cli/internal/api"
+)
+
+type staticToken string
+
+func (t staticToken) GetToken() string { return string(t) }
+
+// fakeAPI answers each path with a fixed status (200 by default) and
+// records the paths requested, in order.
+type fakeAPI struct {
+	mu       sync.Mutex
+	status   map[string]int
+	requests []string
+}
+
+func (f *fakeAPI) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.requests = append(f.requests, r.URL.Path)
+	status := http.StatusOK
+	if s, ok := f.status[r.URL.Path]; ok {
+		status = s
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write([]byte(`{}`))
+}
+
+// setup returns an empty queue in a temp dir, a client for a fake API and
+// an artifact file to register.
+func setup(t *testing.T, status map[string]int) (*Queue, *api.Client, *fakeAPI, string) {
+	t.Helper()
+	fake := &fakeAPI{status: status}
+	srv := httptest.NewServer(fake)
+	t.Cleanup(srv.Close)
+
+	dir := t.TempDir()
+	q := &Queue{path: filepath.Join(dir, "queue.json")}
+	client := api.NewClient(srv.URL, 5, staticToken("tok"))
+
+	file := filepath.Join(dir, "evidence.log")
+	if err := os.WriteFile(file, []byte("evidence"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	return q, client, fake, file
+}
+
+// queueAttachedSignal queues an artifact registration for file and a signal
+// that depends on it, returning the artifact request's ID.
+func queueAttachedSignal(t *testing.T, q *Queue, file string) string {
+	t.Helper()
+	sum := sha256.Sum256([]byte("evidence"))
+	size := int64(len("evidence"))
+	if err := q.Add("POST", api.PathArtifacts, map[string]interface{}{
+		"card_id": "c1", "storage_path": file, "file_hash": hex.EncodeToString(sum[:]), "file_size": size,
+	}); err != nil {
+		t.Fatal(err)
+	}
+	items, err := q.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	artifactID := items[len(items)-1].ID
+	if err := q.AddAfter(artifactID, "POST", api.PathSignals, map[string]interface{}{"signals": []string{}}); err != nil {
+		t.Fatal(err)
+	}
+	return artifactID
+}
+
+func paths(t *testing.T, q *Queue) []string {
+	t.Helper()
+	items, err := q.List()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out []string
+	for _, item := range items {
+		out = append(out, item.Path)
+	}
+	return out
+}
+
+func TestFlushSendsSignalAfterItsArtifact(t *testing.T) {
+	q, client, fake, file := setup(t, nil)
+	queueAttachedSignal(t, q, file)
+
+	flushed, _, err := Flush(q, client, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if flushed != 2 {
+		t.Errorf("flushed %d, want 2", flushed)
+	}
+	want := []string{api.PathArtifacts, api.PathSignals}
+	if len(fake.requests) != 2 || fake.requests[0] != want[0] || fake.requests[1] != want[1] {
+		t.Errorf("requests = %v, want %v", fake.requests, want)
+	}
+}
+
+func TestFlushDropsSignalWhenArtifactFileChanged(t *testing.T) {
+	q, client, fake, file := setup(t, nil)
+	queueAttachedSignal(t, q, file)
+	if err := q.Add("POST", "/unrelated", map[string]string{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(file, []byte("edited while offline"), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	if _, _, err := Flush(q, client, 0); err != nil {
+		t.Fatal(err)
+	}
+	if len(fake.requests) != 1 || fake.requests[0] != "/unrelated" {
+		t.Errorf("requests = %v, want only /unrelated", fake.requests)
+	}
+	if left := paths(t, q); len(left) != 0 {
+		t.Errorf("left in queue: %v", left)
+	}
+}
+
+func TestFlushDropsSignalWhenArtifactRejected(t *testing.T) {
+	q, client, fake, file := setup(t, map[string]int{api.PathArtifacts: http.StatusBadRequest})
+	queueAttachedSignal(t, q, file)
+
+	if _, _, err := Flush(q, client, 0); err != nil {
+		t.Fatal(err)
+	}
+	for _, p := range fake.requests {
+		if p == api.PathSignals {
+			t.Errorf("signal sent although its artifact was rejected")
+		}
+	}
+	if left := paths(t, q); len(left) != 0 {
+		t.Errorf("left in queue: %v", left)
+	}
+}
+
+func TestFlushHoldsSignalWhileArtifactRetried(t *testing.T) {
+	q, client, fake, file := setup(t, map[string]int{api.PathArtifacts: http.StatusConflict})
+	queueAttachedSignal(t, q, file)
+
+	// First flush: the artifact fails but is kept for retry, so the signal
+	// waits with it.
+	if _, _, err := Flush(q, client, 1); err != nil {
+		t.Fatal(err)
+	}
+	if left := paths(t, q); len(left) != 2 {
+		t.Fatalf("left in queue: %v, want artifact and signal", left)
+	}
+
+	// Second flush: the artifact fails again, goes past the limit and takes
+	// the signal with it.
+	_, pruned, err := Flush(q, client, 1)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pruned != 1 {
+		t.Errorf("pruned %d, want 1", pruned)
+	}
+	for _, p := range fake.requests {
+		if p == api.PathSignals {
+			t.Errorf("signal sent although its artifact never registered")
+		}
+	}
+	if left := paths(t, q); len(left) != 0 {
+		t.Errorf("left in queue: %v", left)
+	}
+}
+
+func TestFlushReportsDropsThroughWarnf(t *testing.T) {
+	var warnings []string
+	old := Warnf
+	Warnf = func(format string, args ...interface{}) {
+		warnings = append(warnings, fmt.Sprintf(format, args...))
+	}
+	defer func() { Warnf = old }()
+
+	// Nothing may reach stdout, which --json output owns.
+	r, w, err := os.Pipe()
+	if err != nil {
+		t.Fatal(err)
+	}
+	stdout := os.Stdout
+	os.Stdout = w
+	defer func() { os.Stdout = stdout }()
+
+	q, client, _, file := setup(t, nil)
+	queueAttachedSignal(t, q, file)
+	if err := os.WriteFile(file, []byte("edited while offline"), 0600); err != nil {
+		t.Fatal(err)
+	}
+	_, _, flushErr := Flush(q, client, 0)
+
+	os.Stdout = stdout
+	w.Close()
+	printed, _ := io.ReadAll(r)
+	if flushErr != nil {
+		t.Fatal(flushErr)
+	}
+	if len(printed) != 0 {
+		t.Errorf("Flush wrote to stdout: %q", printed)
+	}
+	if len(warnings) != 2 ||
+		!strings.HasPrefix(warnings[0], "skipping queued artifact") ||
+		!strings.HasPrefix(warnings[1], "dropped queued request POST "+api.PathSignals) {
+		t.Errorf("warnings = %q, want the skipped artifact and its dropped signal", warnings)
+	}
+}
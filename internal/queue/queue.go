@@ -4,10 +4,12 @@ import (
 	"encoding/json"
 	"fmt"
 	"os"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/dea-exmachina/dea-cli/internal/config"
+	"github.com/dea-exmachina/dea-cli/internal/fileutil"
 )
 
 // QueuedRequest represents a failed API call stored for later retry.
@@ -17,6 +19,34 @@ type QueuedRequest struct {
 	Path     string          `json:"path"`
 	Body     json.RawMessage `json:"body"`
 	QueuedAt time.Time       `json:"queued_at"`
+
+	// Attempts counts replays that failed with a 4xx or 5xx response, as
+	// recorded by Flush with pruning enabled.
+	Attempts int `json:"attempts,omitempty"`
+
+	// DependsOn is the ID of a queued request this one refers to, such as
+	// the artifact a signal cites. Flush holds it while that request is
+	// still queued and drops it if that request is dropped.
+	DependsOn string `json:"depends_on,omitempty"`
+}
+
+// Validate checks that a request has everything needed to replay it.
+func (r QueuedRequest) Validate() error {
+	if r.ID == "" {
+		return fmt.Errorf("missing id")
+	}
+	switch r.Method {
+	case "GET", "POST", "DELETE":
+	default:
+		return fmt.Errorf("request %s: unsupported method %q", r.ID, r.Method)
+	}
+	if !strings.HasPrefix(r.Path, "/") {
+		return fmt.Errorf("request %s: path %q must start with /", r.ID, r.Path)
+	}
+	if len(r.Body) > 0 && !json.Valid(r.Body) {
+		return fmt.Errorf("request %s: body is not valid JSON", r.ID)
+	}
+	return nil
 }
 
 // Queue manages offline request persistence at ~/.dea/queue.json.
@@ -32,6 +62,12 @@ func New() *Queue {
 
 // Add appends a request to the queue.
 func (q *Queue) Add(method, path string, body interface{}) error {
+	return q.AddAfter("", method, path, body)
+}
+
+// AddAfter queues a request that depends on the queued request with ID
+// dependsOn; see QueuedRequest.DependsOn. An empty dependsOn is Add.
+func (q *Queue) AddAfter(dependsOn, method, path string, body interface{}) error {
 	q.mu.Lock()
 	defer q.mu.Unlock()
 
@@ -55,6 +91,8 @@ func (q *Queue) Add(method, path string, body interface{}) error {
 		Path:     path,
 		Body:     rawBody,
 		QueuedAt: time.Now().UTC(),
+
+		DependsOn: dependsOn,
 	})
 
 	return q.save(items)
@@ -93,6 +131,87 @@ func (q *Queue) Remove(id string) error {
 	return q.save(filtered)
 }
 
+// Drop removes the request with id and every queued request that depends
+// on it, directly or not, returning the dependents removed.
+func (q *Queue) Drop(id string) ([]QueuedRequest, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items, err := q.load()
+	if err != nil {
+		return nil, err
+	}
+
+	dropped := map[string]bool{id: true}
+	var dependents []QueuedRequest
+	// Dependents are queued after what they depend on, so one pass in
+	// order catches chains.
+	kept := make([]QueuedRequest, 0, len(items))
+	for _, item := range items {
+		switch {
+		case dropped[item.ID]:
+		case item.DependsOn != "" && dropped[item.DependsOn]:
+			dropped[item.ID] = true
+			dependents = append(dependents, item)
+		default:
+			kept = append(kept, item)
+		}
+	}
+	return dependents, q.save(kept)
+}
+
+// RecordFailure counts a failed replay of the request with id and returns
+// its new attempt count.
+func (q *Queue) RecordFailure(id string) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	items, err := q.load()
+	if err != nil {
+		return 0, err
+	}
+	for i := range items {
+		if items[i].ID == id {
+			items[i].Attempts++
+			return items[i].Attempts, q.save(items)
+		}
+	}
+	return 0, fmt.Errorf("queued request %s not found", id)
+}
+
+// Import merges items into the queue, skipping any whose ID is already
+// queued. With replace, the existing queue is discarded first. Returns the
+// number of items added.
+func (q *Queue) Import(items []QueuedRequest, replace bool) (int, error) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	existing := []QueuedRequest{}
+	if !replace {
+		var err error
+		existing, err = q.load()
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	seen := make(map[string]bool, len(existing))
+	for _, item := range existing {
+		seen[item.ID] = true
+	}
+
+	added := 0
+	for _, item := range items {
+		if seen[item.ID] {
+			continue
+		}
+		seen[item.ID] = true
+		existing = append(existing, item)
+		added++
+	}
+	return added, q.save(existing)
+}
+
 // Len returns the number of queued items.
 func (q *Queue) Len() int {
 	q.mu.Lock()
@@ -128,5 +247,5 @@ func (q *Queue) save(items []QueuedRequest) error {
 	if err != nil {
 		return err
 	}
-	return os.WriteFile(q.path, data, 0600)
+	return fileutil.WriteFileAtomic(q.path, data, 0600)
 }